@@ -0,0 +1,228 @@
+// Package backup reads and writes full copies of the suppression list: one
+// JSON entry per line, optionally gzip compressed, described by a manifest
+// recording their counts and checksums.
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	sp "github.com/SparkPost/gosparkpost"
+)
+
+// Format is the format recorded in the manifests.
+const Format = "ndjson"
+
+// ManifestSuffix is appended to the backup file name to locate its manifest.
+const ManifestSuffix = ".manifest.json"
+
+// Entry is a line of a backup. Unlike the output of `suppression list` it
+// keeps every field of the suppression list entry.
+type Entry struct {
+	Recipient        string `json:"recipient"`
+	Transactional    bool   `json:"transactional"`
+	NonTransactional bool   `json:"non_transactional"`
+	// Type is set instead of Transactional and NonTransactional by the
+	// accounts that still report a single type per entry.
+	Type        string `json:"type,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Description string `json:"description"`
+	Created     string `json:"created,omitempty"`
+	Updated     string `json:"updated,omitempty"`
+}
+
+// NewEntry returns the backup entry of a suppression list entry.
+func NewEntry(e *sp.SuppressionEntry) Entry {
+	recipient := e.Recipient
+	if recipient == "" {
+		recipient = e.Email
+	}
+	return Entry{
+		Recipient:        recipient,
+		Transactional:    e.Transactional,
+		NonTransactional: e.NonTransactional,
+		Type:             e.Type,
+		Source:           e.Source,
+		Description:      e.Description,
+		Created:          e.Created,
+		Updated:          e.Updated,
+	}
+}
+
+// Manifest describes a backup file, so it can be checked before it is restored.
+type Manifest struct {
+	File   string    `json:"file"`
+	Format string    `json:"format"`
+	Gzip   bool      `json:"gzip"`
+	Date   time.Time `json:"date"`
+	// Params are the search parameters the entries were exported with.
+	Params map[string]string `json:"params,omitempty"`
+	// Entries counts the lines of the backup, Transactional and
+	// NonTransactional the entries suppressing each type of messages.
+	Entries          int64 `json:"entries"`
+	Transactional    int64 `json:"transactional"`
+	NonTransactional int64 `json:"non_transactional"`
+	// Bytes and SHA256 are the size and digest of the file as written,
+	// ContentSHA256 the digest of the uncompressed lines.
+	Bytes         int64  `json:"bytes"`
+	SHA256        string `json:"sha256"`
+	ContentSHA256 string `json:"content_sha256"`
+}
+
+// ManifestPath returns the manifest location for the backup at file.
+func ManifestPath(file string) string {
+	return file + ManifestSuffix
+}
+
+// IsGzip reports whether the backup at file is written compressed, from its name.
+func IsGzip(file string) bool {
+	return strings.HasSuffix(strings.ToLower(file), ".gz")
+}
+
+// Writer writes a backup file.
+type Writer struct {
+	manifest Manifest
+	f        *os.File
+	file     hash.Hash
+	content  hash.Hash
+	counter  *countingWriter
+	buf      *bufio.Writer
+	gz       *gzip.Writer
+	enc      *json.Encoder
+}
+
+// Create creates the backup file at path, compressed when compress is set.
+func Create(path string, compress bool, params map[string]string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Writer{
+		manifest: Manifest{File: path, Format: Format, Gzip: compress, Date: time.Now().UTC(), Params: params},
+		f:        f,
+		file:     sha256.New(),
+		content:  sha256.New(),
+	}
+	w.counter = &countingWriter{w: io.MultiWriter(f, w.file)}
+	w.buf = bufio.NewWriter(w.counter)
+
+	var out io.Writer = w.buf
+	if compress {
+		w.gz = gzip.NewWriter(w.buf)
+		out = w.gz
+	}
+	w.enc = json.NewEncoder(io.MultiWriter(out, w.content))
+	return w, nil
+}
+
+// Write appends e to the backup.
+func (w *Writer) Write(e Entry) error {
+	if err := w.enc.Encode(e); err != nil {
+		return err
+	}
+
+	w.manifest.Entries++
+	if e.Transactional || e.Type == "transactional" {
+		w.manifest.Transactional++
+	}
+	if e.NonTransactional || e.Type == "non_transactional" {
+		w.manifest.NonTransactional++
+	}
+	return nil
+}
+
+// Close completes the backup file and writes its manifest, which it returns.
+func (w *Writer) Close() (*Manifest, error) {
+	if w.gz != nil {
+		if err := w.gz.Close(); err != nil {
+			w.f.Close()
+			return nil, err
+		}
+	}
+	if err := w.buf.Flush(); err != nil {
+		w.f.Close()
+		return nil, err
+	}
+	if err := w.f.Close(); err != nil {
+		return nil, err
+	}
+
+	w.manifest.Bytes = w.counter.n
+	w.manifest.SHA256 = hex.EncodeToString(w.file.Sum(nil))
+	w.manifest.ContentSHA256 = hex.EncodeToString(w.content.Sum(nil))
+
+	data, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(ManifestPath(w.manifest.File), append(data, '\n'), 0644); err != nil {
+		return nil, err
+	}
+	return &w.manifest, nil
+}
+
+// Remove deletes an incomplete backup file.
+func (w *Writer) Remove() {
+	w.f.Close()
+	os.Remove(w.manifest.File)
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// LoadManifest reads the manifest of the backup at file. It returns nil
+// without error when there is none.
+func LoadManifest(file string) (*Manifest, error) {
+	path := ManifestPath(file)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest '%s': %s", path, err)
+	}
+	return m, nil
+}
+
+// Verify checks that the backup at file has the size and checksum recorded in m.
+func (m *Manifest) Verify(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return err
+	}
+	if n != m.Bytes || hex.EncodeToString(h.Sum(nil)) != m.SHA256 {
+		return fmt.Errorf("'%s' does not match its manifest: expected %d bytes with SHA-256 %s", file, m.Bytes, m.SHA256)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package backup
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var entries = []Entry{
+	{Recipient: "name@example.com", Transactional: true, NonTransactional: true, Source: "Manually Added", Description: "MBL: bad mailbox"},
+	{Recipient: "Name@Bücher.DE", NonTransactional: true, Source: "Spam Complaint", Description: "", Created: "2016-05-02T14:21:37+00:00"},
+	{Recipient: "name@localhost", Type: "transactional", Description: "legacy"},
+}
+
+// readEntries returns the entries of the backup at path.
+func readEntries(t *testing.T, path string, compressed bool) []Entry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if compressed {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = gz
+	}
+
+	var read []Entry
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		read = append(read, e)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return read
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, name := range []string{"suppressions.ndjson", "suppressions.ndjson.gz"} {
+		path := filepath.Join(t.TempDir(), name)
+		w, err := Create(path, IsGzip(path), map[string]string{"types": "transactional"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			if err := w.Write(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+		written, err := w.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := readEntries(t, path, IsGzip(path)); !reflect.DeepEqual(got, entries) {
+			t.Errorf("%s holds %+v, want %+v", name, got, entries)
+		}
+
+		m, err := LoadManifest(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(m, written) {
+			t.Errorf("%s: loaded manifest %+v, want %+v", name, m, written)
+		}
+		if m.Entries != 3 || m.Transactional != 2 || m.NonTransactional != 2 || m.Gzip != IsGzip(path) || m.Params["types"] != "transactional" {
+			t.Errorf("%s: manifest %+v", name, m)
+		}
+		if err := m.Verify(path); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+	}
+}
+
+func TestVerifyChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "suppressions.ndjson")
+	w, err := Create(path, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(entries[0]); err != nil {
+		t.Fatal(err)
+	}
+	m, err := w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("{}\n")
+	f.Close()
+	if err := m.Verify(path); err == nil {
+		t.Error("Verify accepted a changed backup")
+	}
+}
+
+func TestLoadManifestMissing(t *testing.T) {
+	m, err := LoadManifest(filepath.Join(t.TempDir(), "suppressions.ndjson"))
+	if m != nil || err != nil {
+		t.Errorf("LoadManifest() = %v, %v, want no manifest and no error", m, err)
+	}
+}
@@ -0,0 +1,322 @@
+// Package bootstrap owns everything a command needs before it can talk to
+// SparkPost: resolving credentials, building the sp.Client and reporting
+// failures consistently.
+package bootstrap
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	sp "github.com/SparkPost/gosparkpost"
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/config"
+	"github.com/SparkPost/sparkpost-cli/internal/transport"
+)
+
+// DefaultBaseURL is used when no base URL is configured.
+const DefaultBaseURL = "https://api.sparkpost.com"
+
+// Errors returned by Options.Validate.
+var (
+	ErrMissingBaseURL     = errors.New("SparkPost BaseUrl must be set")
+	ErrMissingCredentials = errors.New("SparkPost API key must be set")
+	ErrMissingPassword    = errors.New("SparkPost password must be set when using a username")
+)
+
+// Options holds everything needed to construct a SparkPost client, along
+// with the defaults commands apply to their requests.
+type Options struct {
+	BaseURL    string
+	APIKey     string
+	Username   string
+	Password   string
+	Subaccount string
+	Timezone   string
+	Output     string
+	Verbose    bool
+
+	// MaxAttempts is the number of attempts made for every API request,
+	// see transport.Retry.
+	MaxAttempts int
+	// MaxRPS limits the API requests made per second, unlimited when zero.
+	MaxRPS float64
+
+	// HTTPClient is used for API requests when set, otherwise the
+	// gosparkpost default is used.
+	HTTPClient *http.Client
+}
+
+// FromContext resolves Options for c. Settings are taken from, in order of
+// precedence: command line flags, environment variables, the selected
+// profile of the configuration file and finally the built-in defaults.
+func FromContext(c *cli.Context) (Options, error) {
+	o := Options{
+		BaseURL:    c.GlobalString("baseurl"),
+		APIKey:     c.GlobalString("apikey"),
+		Username:   c.GlobalString("username"),
+		Password:   c.GlobalString("password"),
+		Subaccount: c.GlobalString("subaccount"),
+		Output:     c.GlobalString("output"),
+		Verbose:    Verbose(c),
+
+		MaxAttempts: c.GlobalInt("max-attempts"),
+		MaxRPS:      c.GlobalFloat64("max-rps"),
+	}
+
+	cfg, err := config.Load(ConfigPath(c))
+	if err != nil {
+		return o, err
+	}
+	profile, err := cfg.Profile(c.GlobalString("profile"))
+	if err != nil {
+		return o, err
+	}
+	if profile != nil {
+		o.applyProfile(profile)
+	}
+
+	if o.BaseURL == "" {
+		o.BaseURL = DefaultBaseURL
+	}
+	return o, nil
+}
+
+// applyProfile fills the settings of o that were not given on the command line
+// or in the environment. Credentials are only taken from p as a whole so an
+// API key from the environment is never mixed with a username from the profile.
+func (o *Options) applyProfile(p *config.Profile) {
+	if o.APIKey == "" && o.Username == "" && o.Password == "" {
+		o.APIKey = p.APIKey
+		o.Username = p.Username
+		o.Password = p.Password
+	}
+	if o.BaseURL == "" {
+		o.BaseURL = p.BaseURL
+	}
+	if o.Subaccount == "" {
+		o.Subaccount = p.Subaccount
+	}
+	if o.Timezone == "" {
+		o.Timezone = p.Timezone
+	}
+	if o.Output == "" {
+		o.Output = p.Output
+	}
+	if o.MaxRPS == 0 {
+		o.MaxRPS = p.MaxRPS
+	}
+}
+
+// ConfigPath returns the configuration file selected by `--config`, or the default location.
+func ConfigPath(c *cli.Context) string {
+	if path := c.GlobalString("config"); path != "" {
+		return path
+	}
+	return config.DefaultPath()
+}
+
+// Validate checks that o holds a base URL and a usable set of credentials.
+// An API key takes precedence; otherwise both username and password are required.
+func (o Options) Validate() error {
+	if o.BaseURL == "" {
+		return ErrMissingBaseURL
+	}
+	if o.APIKey != "" {
+		return nil
+	}
+	if o.Username == "" && o.Password == "" {
+		return ErrMissingCredentials
+	}
+	if o.Password == "" {
+		return ErrMissingPassword
+	}
+	return nil
+}
+
+// NewClient validates o and returns an initialised SparkPost client.
+func NewClient(o Options) (*sp.Client, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	cfg := &sp.Config{
+		BaseUrl:    o.BaseURL,
+		ApiKey:     o.APIKey,
+		ApiVersion: 1,
+		Verbose:    o.Verbose,
+	}
+	if o.APIKey == "" {
+		cfg.Username = o.Username
+		cfg.Password = o.Password
+	}
+
+	httpClient := withTransports(o.HTTPClient, o)
+	if o.Subaccount != "" {
+		httpClient = withHeader(httpClient, "X-MSYS-SUBACCOUNT", o.Subaccount)
+	}
+
+	client := &sp.Client{Client: httpClient}
+	if err := client.Init(cfg); err != nil {
+		return nil, fmt.Errorf("SparkPost client init failed: %s", err)
+	}
+
+	return client, nil
+}
+
+// Settings returns the resolved Options for c, exiting with an error message
+// when the configuration file or the requested profile cannot be used.
+func Settings(c *cli.Context) Options {
+	o, err := FromContext(c)
+	if err != nil {
+		Fatal(err)
+	}
+	return o
+}
+
+// Client returns a SparkPost client configured for c, exiting with an error
+// message when that is not possible.
+func Client(c *cli.Context) *sp.Client {
+	return ClientFor(Settings(c))
+}
+
+// ClientFor returns a SparkPost client configured with o, exiting with an
+// error message when that is not possible.
+func ClientFor(o Options) *sp.Client {
+	client, err := NewClient(o)
+	if err != nil {
+		Fatal(err)
+	}
+	return client
+}
+
+// Verbose reports whether `--verbose true` was passed to the application.
+func Verbose(c *cli.Context) bool {
+	return c.GlobalString("verbose") == "true"
+}
+
+// Check returns err, or an error describing the errors of res if it reported any.
+func Check(res *sp.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if res != nil {
+		return Errors(res.Errors)
+	}
+	return nil
+}
+
+// Errors returns an error describing errs, the `errors` array of a SparkPost
+// response, or nil when it is empty.
+func Errors(errs interface{}) error {
+	if errs == nil {
+		return nil
+	}
+	v := reflect.ValueOf(errs)
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Len() == 0 {
+		return nil
+	}
+	return fmt.Errorf("%v", errs)
+}
+
+// Fatal reports err along with the usual hint about verbose mode and exits.
+func Fatal(err error) {
+	log.Fatalf("ERROR: %s\n\nFor additional information try using `--verbose true`\n\n\n", err)
+}
+
+// Fatalf reports a usage problem and exits.
+func Fatalf(format string, v ...interface{}) {
+	log.Fatalf("ERROR: "+format+"\n", v...)
+}
+
+// limiters holds the rate limiter of every client created, see ReportThroughput.
+var (
+	limitersMu sync.Mutex
+	limiters   []*transport.Limiter
+)
+
+// withTransports returns a copy of client (or of a default client when nil)
+// whose requests are rate limited to o.MaxRPS and retried up to o.MaxAttempts
+// times when throttled or failed. Every retry counts against the rate limit.
+func withTransports(client *http.Client, o Options) *http.Client {
+	wrapped := &http.Client{}
+	if client != nil {
+		*wrapped = *client
+	}
+
+	limiter := &transport.Limiter{Next: wrapped.Transport, RPS: o.MaxRPS}
+	limitersMu.Lock()
+	limiters = append(limiters, limiter)
+	limitersMu.Unlock()
+
+	retry := &transport.Retry{Next: limiter, MaxAttempts: o.MaxAttempts}
+	if o.Verbose {
+		retry.Logf = log.Printf
+	}
+	wrapped.Transport = retry
+	return wrapped
+}
+
+// ReportThroughput logs the number of API requests made and their effective
+// rate when `--verbose true` was passed to the application.
+func ReportThroughput(c *cli.Context) {
+	if !Verbose(c) {
+		return
+	}
+
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	for _, l := range limiters {
+		requests, elapsed := l.Throughput()
+		if requests == 0 {
+			continue
+		}
+		rate := float64(requests)
+		if elapsed > 0 {
+			rate = float64(requests) / elapsed.Seconds()
+		}
+		log.Printf("Made %d API requests in %s, %.2f requests/s (limit: %s)", requests, elapsed.Round(time.Millisecond), rate, rpsLimit(l.RPS))
+	}
+}
+
+func rpsLimit(rps float64) string {
+	if rps <= 0 {
+		return "none"
+	}
+	return strconv.FormatFloat(rps, 'f', -1, 64) + " requests/s"
+}
+
+// headerTransport sets a header on every request before handing it to next.
+type headerTransport struct {
+	next  http.RoundTripper
+	name  string
+	value string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(t.name, t.value)
+	return t.next.RoundTrip(req)
+}
+
+// withHeader returns a copy of client (or of a default client when nil) whose
+// requests all carry the given header.
+func withHeader(client *http.Client, name, value string) *http.Client {
+	wrapped := &http.Client{}
+	if client != nil {
+		*wrapped = *client
+	}
+
+	next := wrapped.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	wrapped.Transport = &headerTransport{next: next, name: name, value: value}
+	return wrapped
+}
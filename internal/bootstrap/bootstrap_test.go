@@ -0,0 +1,208 @@
+package bootstrap
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	sp "github.com/SparkPost/gosparkpost"
+	"github.com/codegangsta/cli"
+)
+
+// environment lists the variables read by the application flags.
+var environment = []string{
+	"SPARKPOST_PROFILE", "SPARKPOST_CONFIG", "SPARKPOST_BASEURL", "SPARKPOST_API_KEY",
+	"SPARKPOST_SUBACCOUNT", "SPARKPOST_OUTPUT", "SPARKPOST_MAX_ATTEMPTS", "SPARKPOST_MAX_RPS",
+}
+
+const testConfig = `current: prod
+profiles:
+  prod:
+    base_url: https://api.eu.sparkpost.com
+    api_key: profile-key
+    subaccount: "7"
+    output: json
+    max_rps: 3
+  legacy:
+    username: profile-user
+    password: profile-password
+`
+
+// setenv sets the given variables for the duration of the test, unsetting
+// the other variables of environment.
+func setenv(t *testing.T, values map[string]string) {
+	for _, name := range environment {
+		old, ok := os.LookupEnv(name)
+		if value, set := values[name]; set {
+			os.Setenv(name, value)
+		} else {
+			os.Unsetenv(name)
+		}
+		name := name
+		t.Cleanup(func() {
+			if ok {
+				os.Setenv(name, old)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
+	}
+}
+
+// resolve runs a command with the application flags given by args and
+// returns the Options it resolves, with testConfig as configuration file.
+func resolve(t *testing.T, env map[string]string, args ...string) Options {
+	dir, err := ioutil.TempDir("", "bootstrap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(testConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	setenv(t, env)
+
+	var o Options
+	var resolveErr error
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{
+		cli.StringFlag{Name: "profile", EnvVar: "SPARKPOST_PROFILE"},
+		cli.StringFlag{Name: "config", EnvVar: "SPARKPOST_CONFIG"},
+		cli.StringFlag{Name: "baseurl, u", EnvVar: "SPARKPOST_BASEURL"},
+		cli.StringFlag{Name: "apikey, k", EnvVar: "SPARKPOST_API_KEY"},
+		cli.StringFlag{Name: "username"},
+		cli.StringFlag{Name: "password, p"},
+		cli.StringFlag{Name: "subaccount", EnvVar: "SPARKPOST_SUBACCOUNT"},
+		cli.StringFlag{Name: "output, o", EnvVar: "SPARKPOST_OUTPUT"},
+		cli.IntFlag{Name: "max-attempts", Value: 5, EnvVar: "SPARKPOST_MAX_ATTEMPTS"},
+		cli.Float64Flag{Name: "max-rps", EnvVar: "SPARKPOST_MAX_RPS"},
+		cli.StringFlag{Name: "verbose", Value: "false"},
+	}
+	app.Commands = []cli.Command{{
+		Name: "run",
+		Action: func(c *cli.Context) {
+			o, resolveErr = FromContext(c)
+		},
+	}}
+
+	argv := append([]string{"sparkpost", "--config", path}, args...)
+	if err := app.Run(append(argv, "run")); err != nil {
+		t.Fatal(err)
+	}
+	if resolveErr != nil {
+		t.Fatal(resolveErr)
+	}
+	return o
+}
+
+func TestFromContextPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want Options
+	}{
+		{
+			name: "profile",
+			want: Options{BaseURL: "https://api.eu.sparkpost.com", APIKey: "profile-key", Subaccount: "7", Output: "json", MaxAttempts: 5, MaxRPS: 3},
+		},
+		{
+			name: "environment over profile",
+			env:  map[string]string{"SPARKPOST_API_KEY": "env-key", "SPARKPOST_SUBACCOUNT": "8", "SPARKPOST_MAX_RPS": "1.5"},
+			want: Options{BaseURL: "https://api.eu.sparkpost.com", APIKey: "env-key", Subaccount: "8", Output: "json", MaxAttempts: 5, MaxRPS: 1.5},
+		},
+		{
+			name: "flags over environment",
+			env:  map[string]string{"SPARKPOST_API_KEY": "env-key", "SPARKPOST_BASEURL": "https://env.example.com"},
+			args: []string{"--apikey", "flag-key", "--baseurl", "https://flag.example.com", "--output", "csv", "--max-attempts", "2"},
+			want: Options{BaseURL: "https://flag.example.com", APIKey: "flag-key", Subaccount: "7", Output: "csv", MaxAttempts: 2, MaxRPS: 3},
+		},
+		{
+			name: "credentials not mixed with the profile",
+			args: []string{"--username", "flag-user", "--password", "flag-password"},
+			want: Options{BaseURL: "https://api.eu.sparkpost.com", Username: "flag-user", Password: "flag-password", Subaccount: "7", Output: "json", MaxAttempts: 5, MaxRPS: 3},
+		},
+		{
+			name: "selected profile",
+			env:  map[string]string{"SPARKPOST_PROFILE": "legacy"},
+			want: Options{BaseURL: DefaultBaseURL, Username: "profile-user", Password: "profile-password", MaxAttempts: 5},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := resolve(t, test.env, test.args...); got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		o    Options
+		want error
+	}{
+		{"api key", Options{BaseURL: DefaultBaseURL, APIKey: "key"}, nil},
+		{"username and password", Options{BaseURL: DefaultBaseURL, Username: "user", Password: "secret"}, nil},
+		{"missing key", Options{BaseURL: DefaultBaseURL}, ErrMissingCredentials},
+		{"username without password", Options{BaseURL: DefaultBaseURL, Username: "user"}, ErrMissingPassword},
+		{"missing base URL", Options{APIKey: "key"}, ErrMissingBaseURL},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.o.Validate(); err != test.want {
+				t.Errorf("Validate() = %v, want %v", err, test.want)
+			}
+			if _, err := NewClient(test.o); err != test.want {
+				t.Errorf("NewClient() error = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestNewClientSubaccount(t *testing.T) {
+	var mu sync.Mutex
+	var headers []http.Header
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers = append(headers, r.Header.Clone())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"results": []}`))
+	}))
+	defer server.Close()
+
+	for _, subaccount := range []string{"101", ""} {
+		// The test server certificate is only trusted by server.Client.
+		client, err := NewClient(Options{BaseURL: server.URL, APIKey: "key", Subaccount: subaccount, MaxAttempts: 1, HTTPClient: server.Client()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.SuppressionRetrieve("jane@example.com", &sp.SuppressionPage{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(headers) != 2 {
+		t.Fatalf("server received %d requests, want 2", len(headers))
+	}
+	if got := headers[0].Get("X-MSYS-SUBACCOUNT"); got != "101" {
+		t.Errorf("X-MSYS-SUBACCOUNT = %q, want 101", got)
+	}
+	if _, ok := headers[1]["X-Msys-Subaccount"]; ok {
+		t.Errorf("X-MSYS-SUBACCOUNT sent without a subaccount: %q", headers[1].Get("X-MSYS-SUBACCOUNT"))
+	}
+	for i, h := range headers {
+		if h.Get("Authorization") == "" {
+			t.Errorf("request %d sent without credentials", i+1)
+		}
+	}
+}
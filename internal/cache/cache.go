@@ -0,0 +1,192 @@
+// Package cache keeps a local copy of the suppression list in a bolt
+// database, so that recipients can be looked up without calling the API.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+
+	"github.com/SparkPost/sparkpost-cli/internal/backup"
+)
+
+var (
+	entriesBucket = []byte("entries")
+	metaBucket    = []byte("meta")
+	metaKey       = []byte("meta")
+)
+
+// Meta describes the content of a cache.
+type Meta struct {
+	// BaseURL, Subaccount and Account identify the suppression list cached.
+	// Account is a fingerprint of the credentials, never the credentials.
+	BaseURL    string `json:"base_url"`
+	Subaccount string `json:"subaccount,omitempty"`
+	Account    string `json:"account"`
+	// Synced is the time up to which the entries were last refreshed, and
+	// FullSync that of the last complete download of the list.
+	Synced   time.Time `json:"synced"`
+	FullSync time.Time `json:"full_sync"`
+	// Entries is the number of recipients cached.
+	Entries int64 `json:"entries"`
+}
+
+// DefaultPath returns the default location of the cache:
+// $XDG_CACHE_HOME/sparkpost/suppressions.db, or ~/.cache/sparkpost/suppressions.db.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CACHE_HOME")
+	if dir == "" {
+		home := os.Getenv("HOME")
+		if home == "" {
+			home = os.Getenv("USERPROFILE")
+		}
+		dir = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(dir, "sparkpost", "suppressions.db")
+}
+
+// Cache is an open cache file.
+type Cache struct {
+	db   *bolt.DB
+	path string
+}
+
+// Open opens the cache at path, creating it unless readOnly. It returns nil
+// without error when readOnly and there is no cache at path.
+func Open(path string, readOnly bool) (*Cache, error) {
+	if readOnly {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, nil
+		}
+	} else if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache '%s': %s", path, err)
+	}
+	if !readOnly {
+		err = db.Update(func(tx *bolt.Tx) error {
+			for _, name := range [][]byte{entriesBucket, metaBucket} {
+				if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to initialize cache '%s': %s", path, err)
+		}
+	}
+	return &Cache{db: db, path: path}, nil
+}
+
+// Path returns the location of the cache.
+func (c *Cache) Path() string {
+	return c.path
+}
+
+// Close closes the cache.
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+// Meta returns the description of the cache, nil when it was never synced.
+func (c *Cache) Meta() (*Meta, error) {
+	var meta *Meta
+	err := c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(metaBucket)
+		if b == nil {
+			return nil
+		}
+		data := b.Get(metaKey)
+		if data == nil {
+			return nil
+		}
+		meta = &Meta{}
+		return json.Unmarshal(data, meta)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid cache '%s': %s", c.path, err)
+	}
+	return meta, nil
+}
+
+// SetMeta records meta, counting the recipients cached.
+func (c *Cache) SetMeta(meta *Meta) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		meta.Entries = int64(tx.Bucket(entriesBucket).Stats().KeyN)
+		data, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(metaBucket).Put(metaKey, data)
+	})
+}
+
+// Put stores entries, replacing the entries of the same recipient and type.
+// Entries without type, from accounts where one entry holds both flags,
+// replace every entry of their recipient, and are replaced by any typed entry
+// of their recipient: the cache never mixes both forms for a recipient.
+func (c *Cache) Put(entries []backup.Entry) error {
+	return c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(entriesBucket)
+		for _, e := range entries {
+			key := []byte(strings.ToLower(e.Recipient))
+			var cached []backup.Entry
+			if data := b.Get(key); data != nil {
+				if err := json.Unmarshal(data, &cached); err != nil {
+					return fmt.Errorf("invalid entry '%s': %s", key, err)
+				}
+			}
+
+			merged := []backup.Entry{}
+			for _, old := range cached {
+				if e.Type != "" && old.Type != "" && old.Type != e.Type {
+					merged = append(merged, old)
+				}
+			}
+			merged = append(merged, e)
+
+			data, err := json.Marshal(merged)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(key, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Lookup returns the entries cached for each of recipients, in the same
+// order, none for those that are not suppressed. Recipients are compared
+// case-insensitively.
+func (c *Cache) Lookup(recipients []string) ([][]backup.Entry, error) {
+	found := make([][]backup.Entry, len(recipients))
+	err := c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(entriesBucket)
+		if b == nil {
+			return nil
+		}
+		for i, recipient := range recipients {
+			data := b.Get([]byte(strings.ToLower(recipient)))
+			if data == nil {
+				continue
+			}
+			if err := json.Unmarshal(data, &found[i]); err != nil {
+				return fmt.Errorf("invalid entry '%s': %s", recipient, err)
+			}
+		}
+		return nil
+	})
+	return found, err
+}
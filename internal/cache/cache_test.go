@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/SparkPost/sparkpost-cli/internal/backup"
+)
+
+// openTemp opens a new cache in a temporary directory.
+func openTemp(t *testing.T) *Cache {
+	t.Helper()
+	c, err := Open(filepath.Join(t.TempDir(), "sparkpost", "suppressions.db"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// lookup returns the entries cached for recipient.
+func lookup(t *testing.T, c *Cache, recipient string) []backup.Entry {
+	t.Helper()
+	found, err := c.Lookup([]string{recipient})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return found[0]
+}
+
+func TestPutMerge(t *testing.T) {
+	transactional := backup.Entry{Recipient: "name@example.com", Type: "transactional", Description: "first"}
+	nonTransactional := backup.Entry{Recipient: "name@example.com", Type: "non_transactional", Description: "second"}
+	updated := backup.Entry{Recipient: "name@example.com", Type: "transactional", Description: "updated"}
+	both := backup.Entry{Recipient: "name@example.com", Transactional: true, NonTransactional: true, Description: "both"}
+
+	tests := []struct {
+		name string
+		puts [][]backup.Entry
+		want []backup.Entry
+	}{
+		{"types add up", [][]backup.Entry{{transactional}, {nonTransactional}}, []backup.Entry{transactional, nonTransactional}},
+		{"same type replaced", [][]backup.Entry{{transactional, nonTransactional}, {updated}}, []backup.Entry{nonTransactional, updated}},
+		{"untyped replaces all", [][]backup.Entry{{transactional, nonTransactional}, {both}}, []backup.Entry{both}},
+		{"typed replaces untyped", [][]backup.Entry{{both}, {transactional}}, []backup.Entry{transactional}},
+		{"untyped replaced", [][]backup.Entry{{both}, {both}}, []backup.Entry{both}},
+	}
+
+	for _, test := range tests {
+		c := openTemp(t)
+		for _, entries := range test.puts {
+			if err := c.Put(entries); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := lookup(t, c, "name@example.com"); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: cached %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	c := openTemp(t)
+	entry := backup.Entry{Recipient: "Name@Example.com", NonTransactional: true}
+	if err := c.Put([]backup.Entry{entry}); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := c.Lookup([]string{"other@example.com", "NAME@example.COM", "name@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]backup.Entry{nil, {entry}, {entry}}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("Lookup() = %+v, want %+v", found, want)
+	}
+}
+
+func TestMeta(t *testing.T) {
+	c := openTemp(t)
+	if meta, err := c.Meta(); meta != nil || err != nil {
+		t.Fatalf("Meta() = %+v, %v before any sync", meta, err)
+	}
+
+	err := c.Put([]backup.Entry{
+		{Recipient: "one@example.com", Type: "transactional"},
+		{Recipient: "ONE@example.com", Type: "non_transactional"},
+		{Recipient: "two@example.com", Transactional: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetMeta(&Meta{BaseURL: "https://api.sparkpost.com", Account: "fingerprint"}); err != nil {
+		t.Fatal(err)
+	}
+
+	meta, err := c.Meta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Entries != 2 || meta.Account != "fingerprint" {
+		t.Errorf("Meta() = %+v, want 2 recipients of the account", meta)
+	}
+}
+
+func TestOpenReadOnlyMissing(t *testing.T) {
+	c, err := Open(filepath.Join(t.TempDir(), "suppressions.db"), true)
+	if c != nil || err != nil {
+		t.Errorf("Open() = %v, %v, want no cache and no error", c, err)
+	}
+}
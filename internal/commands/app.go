@@ -0,0 +1,144 @@
+// Package commands implements the subcommands of the unified `sparkpost` CLI.
+package commands
+
+import (
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/bootstrap"
+	"github.com/SparkPost/sparkpost-cli/internal/output"
+	"github.com/SparkPost/sparkpost-cli/internal/transport"
+)
+
+// NewApp returns the `sparkpost` application with every command group registered.
+func NewApp() *cli.App {
+	app := cli.NewApp()
+
+	app.Version = "0.1.0"
+	app.Name = "sparkpost"
+	app.Usage = "SparkPost CLI\n\n\tSee https://developers.sparkpost.com/api/"
+	app.Flags = []cli.Flag{
+		// Core Client Configuration
+		cli.StringFlag{
+			Name:   "profile",
+			Value:  "",
+			Usage:  "Optional name of the configuration profile to use. Defaults to the profile selected with `profile use`",
+			EnvVar: "SPARKPOST_PROFILE",
+		},
+		cli.StringFlag{
+			Name:   "config",
+			Value:  "",
+			Usage:  "Optional path of the configuration file. Default: ~/.config/sparkpost/config.yaml",
+			EnvVar: "SPARKPOST_CONFIG",
+		},
+		cli.StringFlag{
+			Name:   "baseurl, u",
+			Value:  "",
+			Usage:  "Optional baseUrl for SparkPost. Default: https://api.sparkpost.com",
+			EnvVar: "SPARKPOST_BASEURL",
+		},
+		cli.StringFlag{
+			Name:   "apikey, k",
+			Value:  "",
+			Usage:  "Required SparkPost API key",
+			EnvVar: "SPARKPOST_API_KEY",
+		},
+		cli.StringFlag{
+			Name:  "username",
+			Value: "",
+			Usage: "Username this is a special case it is more common to use apikey",
+		},
+		cli.StringFlag{
+			Name:  "password, p",
+			Value: "",
+			Usage: "Password this is a special case it is more common to use apikey",
+		},
+		cli.StringFlag{
+			Name:   "subaccount",
+			Value:  "",
+			Usage:  "Optional subaccount ID to act on behalf of. Example: 101",
+			EnvVar: "SPARKPOST_SUBACCOUNT",
+		},
+		cli.StringFlag{
+			Name:   "output, o",
+			Value:  "",
+			Usage:  "Optional output format, one of " + strings.Join(output.Formats, ", ") + ". Default depends on the command",
+			EnvVar: "SPARKPOST_OUTPUT",
+		},
+		cli.IntFlag{
+			Name:   "max-attempts",
+			Value:  transport.DefaultMaxAttempts,
+			Usage:  "Attempts per API request when SparkPost throttles (429) or fails (5xx). Use 1 to disable retries",
+			EnvVar: "SPARKPOST_MAX_ATTEMPTS",
+		},
+		cli.Float64Flag{
+			Name:   "max-rps",
+			Value:  0,
+			Usage:  "Optional limit of API requests per second, shared by all requests of the command. Example: 2.5",
+			EnvVar: "SPARKPOST_MAX_RPS",
+		},
+		cli.StringFlag{
+			Name:  "verbose",
+			Value: "false",
+			Usage: "Dumps additional information to console",
+		},
+	}
+	app.Commands = []cli.Command{
+		suppressionCommand,
+		eventsCommand,
+		webhooksCommand,
+		metricsCommand,
+		profileCommand,
+	}
+	app.After = func(c *cli.Context) error {
+		bootstrap.ReportThroughput(c)
+		return nil
+	}
+
+	return app
+}
+
+// collectParameters returns the API query parameters for every non-empty flag in names.
+// The timezone of the selected profile is used when the command accepts one and none was given.
+func collectParameters(c *cli.Context, names []string) map[string]string {
+	parameters := make(map[string]string)
+
+	for _, name := range names {
+		if c.String(name) != "" {
+			parameters[name] = c.String(name)
+		} else if name == "timezone" {
+			if tz := bootstrap.Settings(c).Timezone; tz != "" {
+				parameters[name] = tz
+			}
+		}
+	}
+
+	return parameters
+}
+
+// outputFormat returns the format selected with `--output` (or the profile), otherwise fallback.
+func outputFormat(c *cli.Context, fallback string) string {
+	if format := bootstrap.Settings(c).Output; format != "" {
+		return format
+	}
+	return fallback
+}
+
+// newOutput returns a writer on stdout for records with the given columns,
+// using the format returned by outputFormat.
+func newOutput(c *cli.Context, fallback string, columns ...string) output.Writer {
+	out, err := output.New(os.Stdout, outputFormat(c, fallback), columns)
+	if err != nil {
+		bootstrap.Fatalf("%s", err)
+	}
+	return out
+}
+
+// closeOutput flushes out, exiting if the output could not be written.
+func closeOutput(out output.Writer) {
+	if err := out.Close(); err != nil {
+		bootstrap.Fatal(err)
+	}
+}
@@ -0,0 +1,301 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+
+	sp "github.com/SparkPost/gosparkpost"
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/bootstrap"
+	"github.com/SparkPost/sparkpost-cli/internal/output"
+)
+
+var eventsSearchParameters = []string{
+	"bounce_classes", "campaign_ids", "events", "friendly_froms", "from",
+	"message_ids", "page", "per_page", "reason", "recipients", "template_ids",
+	"timezone", "to", "transmission_ids", "subaccounts",
+}
+
+var eventsCommand = cli.Command{
+	Name:  "events",
+	Usage: "Search message events\n\n\tSee also https://developers.sparkpost.com/api/message-events.html#message-events-message-events-get",
+	Subcommands: []cli.Command{
+		{
+			Name:   "search",
+			Usage:  "Perform a filtered search for message event data",
+			Action: eventsSearch,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "fields",
+					Value: "",
+					Usage: "Optional comma-delimited list of event fields to output as columns, nested fields use dots. Example: type,timestamp,rcpt_to,geo_ip.country",
+				},
+				cli.StringFlag{
+					Name:  "pause",
+					Value: "0",
+					Usage: "Deprecated, use the global --max-rps option. Seconds to pause between requests, the same as --max-rps 1/pause",
+				},
+
+				// Event Search Parameters
+				cli.StringFlag{
+					Name:  "bounce_classes, b",
+					Value: "",
+					Usage: "Optional comma-delimited list of bounce classification codes to search.",
+				},
+				cli.StringFlag{
+					Name:  "campaign_ids, i",
+					Value: "",
+					Usage: "Optional comma-delimited list of campaign ID's to search. Example: \"Example Campaign Name\"",
+				},
+				cli.StringFlag{
+					Name:  "events, e",
+					Value: "",
+					Usage: "Optional comma-delimited list of event types to search. Defaults to all event types.",
+				},
+				cli.StringFlag{
+					Name:  "friendly_froms",
+					Value: "",
+					Usage: "Optional comma-delimited list of friendly_froms to search",
+				},
+				cli.StringFlag{
+					Name:  "from, f",
+					Value: "",
+					Usage: "Optional Datetime in format of YYYY-MM-DDTHH:MM. Example: 2016-02-10T08:00. Default: One hour ago",
+				},
+				cli.StringFlag{
+					Name:  "message_ids",
+					Value: "",
+					Usage: "Optional Comma-delimited list of message ID's to search. Example: 0e0d94b7-9085-4e3c-ab30-e3f2cd9c273e.",
+				},
+				cli.StringFlag{
+					Name:  "page",
+					Value: "",
+					Usage: "Optional results page number to return. Used with per_page for paging through result. Example: 25. Default: 1",
+				},
+				cli.StringFlag{
+					Name:  "per_page",
+					Value: "",
+					Usage: "Optional number of results to return per page. Must be between 1 and 10,000 (inclusive). Example: 100. Default: 1000.",
+				},
+				cli.StringFlag{
+					Name:  "reason",
+					Value: "",
+					Usage: "Optional bounce/failure/rejection reason that will be matched using a wildcard (e.g., %%reason%%). Example: bounce.",
+				},
+				cli.StringFlag{
+					Name:  "recipients",
+					Value: "",
+					Usage: "Optional Comma-delimited list of recipients to search. Example: recipient@example.com",
+				},
+				cli.StringFlag{
+					Name:  "template_ids",
+					Value: "",
+					Usage: "Optional Comma-delimited list of template ID's to search. Example: templ-1234.",
+				},
+				cli.StringFlag{
+					Name:  "timezone",
+					Value: "",
+					Usage: "Optional Standard timezone identification string. Example: America/New_York. Default: UTC",
+				},
+				cli.StringFlag{
+					Name:  "to",
+					Value: "",
+					Usage: "Optional Datetime in format of YYYY-MM-DDTHH:MM. Example: 2016-02-10T00:00. Default: now.",
+				},
+				cli.StringFlag{
+					Name:  "transmission_ids",
+					Value: "",
+					Usage: "Optional Comma-delimited list of transmission ID's to search (i.e. id generated during creation of a transmission). Example: 65832150921904138.",
+				},
+				cli.StringFlag{
+					Name:  "subaccounts",
+					Value: "",
+					Usage: "Optional Comma-delimited list of subaccount ID's to search. Example: 101",
+				},
+			},
+		},
+	},
+}
+
+func eventsSearch(c *cli.Context) {
+	settings := bootstrap.Settings(c)
+	if pause := c.Int64("pause"); pause > 0 {
+		log.Printf("WARN: --pause is deprecated, use the global --max-rps option instead")
+		if settings.MaxRPS == 0 {
+			settings.MaxRPS = 1 / float64(pause)
+		}
+	}
+	client := bootstrap.ClientFor(settings)
+	isVerbose := bootstrap.Verbose(c)
+
+	eventPage := &sp.EventsPage{}
+	eventPage.Params = collectParameters(c, eventsSearchParameters)
+
+	r, err := client.MessageEventsSearch(eventPage)
+	totalCount := eventPage.TotalCount
+
+	if err != nil {
+		bootstrap.Fatal(err)
+		return
+	}
+
+	out := newEventsWriter(c)
+
+	for {
+		if eventPage == nil {
+			if isVerbose {
+				log.Printf("Event page nil")
+			}
+			break
+		}
+
+		if err := bootstrap.Errors(eventPage.Errors); err != nil {
+			bootstrap.Fatal(err)
+			break
+		}
+
+		if len(eventPage.Events) == 0 {
+			if isVerbose {
+				log.Printf("Dump: %v", r)
+				log.Printf("No more events")
+			}
+			break
+		}
+
+		for _, doc := range eventDocuments(r, eventPage) {
+			out.write(doc)
+		}
+
+		if c.String("page") != "" {
+			break
+		}
+
+		if isVerbose {
+			log.Printf("NextPage(): %s", eventPage.NextPage)
+		}
+		eventPage, r, err = eventPage.Next()
+		if err != nil {
+			bootstrap.Fatal(err)
+			break
+		}
+
+	}
+
+	out.close()
+
+	log.Printf("\t-------------------\n")
+	log.Printf("\tResult Count: %d\n", totalCount)
+}
+
+// eventColumns names the event fields written as columns when no --fields are given
+// and the output format cannot hold whole events.
+var eventColumns = []string{
+	"type", "timestamp", "message_id", "transmission_id", "campaign_id", "template_id",
+	"rcpt_to", "friendly_from", "subject", "bounce_class", "raw_reason",
+}
+
+// eventsWriter writes message events either as whole documents, keeping every
+// field SparkPost returned, or projected onto a set of columns.
+type eventsWriter struct {
+	docs    output.DocumentWriter
+	records output.Writer
+	columns []string
+}
+
+func newEventsWriter(c *cli.Context) *eventsWriter {
+	format := outputFormat(c, output.NDJSON)
+
+	var columns []string
+	for _, field := range strings.Split(c.String("fields"), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			columns = append(columns, field)
+		}
+	}
+
+	if len(columns) == 0 && output.IsDocumentFormat(format) {
+		docs, err := output.NewDocumentWriter(os.Stdout, format)
+		if err != nil {
+			bootstrap.Fatalf("%s", err)
+		}
+		return &eventsWriter{docs: docs}
+	}
+
+	if len(columns) == 0 {
+		columns = eventColumns
+	}
+	return &eventsWriter{records: newOutput(c, format, columns...), columns: columns}
+}
+
+func (w *eventsWriter) write(doc json.RawMessage) {
+	if w.docs != nil {
+		if err := w.docs.Write(doc); err != nil {
+			bootstrap.Fatal(err)
+		}
+		return
+	}
+
+	var event map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+	if err := decoder.Decode(&event); err != nil {
+		bootstrap.Fatal(err)
+	}
+
+	values := make([]interface{}, len(w.columns))
+	for i, column := range w.columns {
+		values[i] = eventField(event, column)
+	}
+	if err := w.records.Write(values...); err != nil {
+		bootstrap.Fatal(err)
+	}
+}
+
+func (w *eventsWriter) close() {
+	if w.docs != nil {
+		if err := w.docs.Close(); err != nil {
+			bootstrap.Fatal(err)
+		}
+		return
+	}
+	closeOutput(w.records)
+}
+
+// eventField returns the value at path in event, where path separates nested field names with dots.
+func eventField(event map[string]interface{}, path string) interface{} {
+	var value interface{} = event
+	for _, name := range strings.Split(path, ".") {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = fields[name]
+	}
+	return value
+}
+
+// eventDocuments returns the events of eventPage exactly as SparkPost returned
+// them in r, or re-encodes the decoded events when the raw response is not available.
+func eventDocuments(r *sp.Response, eventPage *sp.EventsPage) []json.RawMessage {
+	if r != nil && len(r.Body) > 0 {
+		var body struct {
+			Results []json.RawMessage `json:"results"`
+		}
+		if err := json.Unmarshal(r.Body, &body); err == nil && len(body.Results) == len(eventPage.Events) {
+			return body.Results
+		}
+	}
+
+	docs := make([]json.RawMessage, 0, len(eventPage.Events))
+	for _, event := range eventPage.Events {
+		doc, err := json.Marshal(event)
+		if err != nil {
+			bootstrap.Fatal(err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
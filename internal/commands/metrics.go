@@ -0,0 +1,254 @@
+package commands
+
+import (
+	"log"
+	"strings"
+
+	sp "github.com/SparkPost/gosparkpost"
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/bootstrap"
+	"github.com/SparkPost/sparkpost-cli/internal/output"
+)
+
+var metricsParameters = []string{
+	"from", "to", "domains", "campaigns", "templates", "nodes", "bindings",
+	"binding_groups", "protocols", "metrics", "timezone", "limit", "order_by", "subaccounts",
+}
+
+var metricsFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "from, f",
+		Value: "",
+		Usage: "Required Datetime in format of YYYY-MM-DDTHH:MM. Example: 2016-02-10T08:00. Default: One hour ago",
+	},
+	cli.StringFlag{
+		Name:  "to",
+		Value: "",
+		Usage: "Optional Datetime in format of YYYY-MM-DDTHH:MM. Example: 2016-02-10T00:00. Default: now.",
+	},
+	cli.StringFlag{
+		Name:  "domains, d",
+		Value: "",
+		Usage: "Optional Comma-delimited list of domains to include Example: gmail.com,yahoo.com,hotmail.com.",
+	},
+	cli.StringFlag{
+		Name:  "campaigns, c",
+		Value: "",
+		Usage: "Optional Comma-delimited list of campaigns to include. Example: Black Friday",
+	},
+	cli.StringFlag{
+		Name:  "metrics, m",
+		Value: "count_injected,count_bounce,count_rejected,count_delivered,count_delivered_first,count_delivered_subsequent,total_delivery_time_first,total_delivery_time_subsequent,total_msg_volume,count_policy_rejection,count_generation_rejection,count_generation_failed,count_inband_bounce,count_outofband_bounce,count_soft_bounce,count_hard_bounce,count_block_bounce,count_admin_bounce,count_undetermined_bounce,count_delayed,count_delayed_first,count_rendered,count_unique_rendered,count_unique_confirmed_opened,count_clicked,count_unique_clicked,count_targeted,count_sent,count_accepted,count_spam_complaint",
+		Usage: "Required Comma-delimited list of metrics for filtering",
+	},
+	cli.StringFlag{
+		Name:  "templates",
+		Value: "",
+		Usage: "Optioanl comma-delimited list of template IDs to include Example: summer-sale",
+	},
+	cli.StringFlag{
+		Name:  "nodes",
+		Value: "",
+		Usage: "Optional comma-delimited list of nodes to include ( Note: SparkPost Elite only ) Example: Email-MSys-1,Email-MSys-2,Email-MSys-3",
+	},
+	cli.StringFlag{
+		Name:  "bindings",
+		Value: "",
+		Usage: "Optional comma-delimited list of bindings to include (Note: SparkPost Elite only) Example: Confirmation",
+	},
+	cli.StringFlag{
+		Name:  "binding_groups",
+		Value: "",
+		Usage: "Optional comma-delimited list of binding groups to include (Note: SparkPost Elite only) Example: Transaction",
+	},
+	cli.StringFlag{
+		Name:  "protocols",
+		Value: "",
+		Usage: "Optional comma-delimited list of protocols for filtering (Note: SparkPost Elite only) Example: smtp",
+	},
+	cli.StringFlag{
+		Name:  "timezone",
+		Value: "",
+		Usage: "Standard timezone identification string, defaults to UTC Example: America/New_York.",
+	},
+	cli.StringFlag{
+		Name:  "limit",
+		Value: "",
+		Usage: "Optional maximum number of results to return Example: 5",
+	},
+	cli.StringFlag{
+		Name:  "order_by",
+		Value: "",
+		Usage: "Optional metric by which to order results Example: count_injected",
+	},
+	cli.StringFlag{
+		Name:  "subaccounts",
+		Value: "",
+		Usage: "Optional Comma-delimited list of subaccount ID's to search. Example: 101",
+	},
+}
+
+var metricsCommand = cli.Command{
+	Name:  "metrics",
+	Usage: "Query deliverability metrics\n\n\tSee https://developers.sparkpost.com/api/metrics.html",
+	Subcommands: []cli.Command{
+		metricsSubcommand("domain", "Deliverability metrics grouped by domain"),
+		metricsSubcommand("binding", "Deliverability metrics grouped by binding (SparkPost Elite or Momentum only)"),
+		metricsSubcommand("binding-group", "Deliverability metrics grouped by binding group (SparkPost Elite or Momentum only)"),
+		metricsSubcommand("campaign", "Deliverability metrics grouped by campaign"),
+		metricsSubcommand("template", "Deliverability metrics grouped by template"),
+		metricsSubcommand("watched-domain", "Deliverability metrics grouped by watched domain"),
+		metricsSubcommand("time-series", "Deliverability metrics ordered by a precision of time"),
+	},
+}
+
+// metricsSubcommand returns the command reporting metrics grouped by name.
+func metricsSubcommand(name, usage string) cli.Command {
+	return cli.Command{
+		Name:  name,
+		Usage: usage,
+		Flags: metricsFlags,
+		Action: func(c *cli.Context) {
+			metricsQuery(c, name)
+		},
+	}
+}
+
+func metricsQuery(c *cli.Context, command string) {
+	metrics := c.String("metrics")
+	fields := strings.Split(metrics, ",")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+		if _, ok := metricValue(&sp.MetricItem{}, fields[i]); !ok {
+			bootstrap.Fatalf("Unknown metric '%s'. See README.md for the list of metric names.", fields[i])
+		}
+	}
+	if bootstrap.Verbose(c) {
+		log.Printf("Metrics: %s", metrics)
+	}
+
+	client := bootstrap.Client(c)
+
+	m := &sp.Metrics{}
+	m.Params = collectParameters(c, metricsParameters)
+
+	if err := bootstrap.Check(client.QueryMetrics(m)); err != nil {
+		bootstrap.Fatal(err)
+	}
+
+	// TODO: add an HTML output
+	out := newOutput(c, output.CSV, append([]string{metricsKeyColumns[command]}, fields...)...)
+
+	for _, element := range m.Results {
+		values := []interface{}{metricsKey(command, &element)}
+		for _, field := range fields {
+			value, _ := metricValue(&element, field)
+			values = append(values, value)
+		}
+
+		if err := out.Write(values...); err != nil {
+			bootstrap.Fatal(err)
+		}
+	}
+	closeOutput(out)
+}
+
+// metricsKeyColumns names the column holding the value results are grouped by for each command.
+var metricsKeyColumns = map[string]string{
+	"domain":         "domain",
+	"binding":        "binding",
+	"binding-group":  "binding_group",
+	"campaign":       "campaign_id",
+	"template":       "template_id",
+	"watched-domain": "watched_domain",
+	"time-series":    "ts",
+}
+
+// metricsKey returns the value metricItem is grouped by for command.
+func metricsKey(command string, metricItem *sp.MetricItem) string {
+	switch command {
+	case "domain":
+		return metricItem.Domain
+	case "campaign":
+		return metricItem.CampaignId
+	case "template":
+		return metricItem.TemplateId
+	case "time-series":
+		return metricItem.TimeStamp
+	case "watched-domain":
+		return metricItem.WatchedDomain
+	case "binding":
+		return metricItem.Binding
+	case "binding-group":
+		return metricItem.BindingGroup
+	}
+	return ""
+}
+
+// metricValue returns the value of the named metric, or false if the metric is unknown.
+func metricValue(metricItem *sp.MetricItem, field string) (int, bool) {
+	switch field {
+	case "count_injected":
+		return metricItem.CountInjected, true
+	case "count_bounce":
+		return metricItem.CountBounce, true
+	case "count_rejected":
+		return metricItem.CountRejected, true
+	case "count_delivered":
+		return metricItem.CountDelivered, true
+	case "count_delivered_first":
+		return metricItem.CountDeliveredFirst, true
+	case "count_delivered_subsequent":
+		return metricItem.CountDeliveredSubsequent, true
+	case "total_delivery_time_first":
+		return metricItem.TotalDeliveryTimeFirst, true
+	case "total_delivery_time_subsequent":
+		return metricItem.TotalDeliveryTimeSubsequent, true
+	case "total_msg_volume":
+		return metricItem.TotalMsgVolume, true
+	case "count_policy_rejection":
+		return metricItem.CountPolicyRejection, true
+	case "count_generation_rejection":
+		return metricItem.CountGenerationRejection, true
+	case "count_generation_failed":
+		return metricItem.CountGenerationFailed, true
+	case "count_inband_bounce":
+		return metricItem.CountInbandBounce, true
+	case "count_outofband_bounce":
+		return metricItem.CountOutofbandBounce, true
+	case "count_soft_bounce":
+		return metricItem.CountSoftBounce, true
+	case "count_hard_bounce":
+		return metricItem.CountHardBounce, true
+	case "count_block_bounce":
+		return metricItem.CountBlockBounce, true
+	case "count_admin_bounce":
+		return metricItem.CountAdminBounce, true
+	case "count_undetermined_bounce":
+		return metricItem.CountUndeterminedBounce, true
+	case "count_delayed":
+		return metricItem.CountDelayed, true
+	case "count_delayed_first":
+		return metricItem.CountDelayedFirst, true
+	case "count_rendered":
+		return metricItem.CountRendered, true
+	case "count_unique_rendered":
+		return metricItem.CountUniqueRendered, true
+	case "count_unique_confirmed_opened":
+		return metricItem.CountUniqueConfirmedOpened, true
+	case "count_clicked":
+		return metricItem.CountClicked, true
+	case "count_unique_clicked":
+		return metricItem.CountUniqueClicked, true
+	case "count_targeted":
+		return metricItem.CountTargeted, true
+	case "count_sent":
+		return metricItem.CountSent, true
+	case "count_accepted":
+		return metricItem.CountAccepted, true
+	case "count_spam_complaint":
+		return metricItem.CountSpamComplaint, true
+	}
+	return 0, false
+}
@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/bootstrap"
+	"github.com/SparkPost/sparkpost-cli/internal/config"
+	"github.com/SparkPost/sparkpost-cli/internal/output"
+)
+
+var profileCommand = cli.Command{
+	Name:  "profile",
+	Usage: "Manage named configuration profiles\n\n\tProfiles are stored in ~/.config/sparkpost/config.yaml unless --config is given.",
+	Subcommands: []cli.Command{
+		{
+			Name:      "add",
+			Usage:     "Create a profile, or update the settings of an existing one",
+			ArgsUsage: "NAME",
+			Action:    profileAdd,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "baseurl",
+					Value: "",
+					Usage: "Optional baseUrl for SparkPost. Example: https://api.eu.sparkpost.com",
+				},
+				cli.StringFlag{
+					Name:  "apikey",
+					Value: "",
+					Usage: "SparkPost API key",
+				},
+				cli.StringFlag{
+					Name:  "username",
+					Value: "",
+					Usage: "Username this is a special case it is more common to use apikey",
+				},
+				cli.StringFlag{
+					Name:  "password",
+					Value: "",
+					Usage: "Password this is a special case it is more common to use apikey",
+				},
+				cli.StringFlag{
+					Name:  "subaccount",
+					Value: "",
+					Usage: "Optional default subaccount ID. Example: 101",
+				},
+				cli.StringFlag{
+					Name:  "timezone",
+					Value: "",
+					Usage: "Optional default timezone for events, metrics and webhooks. Example: America/New_York",
+				},
+				cli.StringFlag{
+					Name:  "output",
+					Value: "",
+					Usage: "Optional default output format, one of json, ndjson, csv, tsv, table, yaml",
+				},
+				cli.Float64Flag{
+					Name:  "max-rps",
+					Value: 0,
+					Usage: "Optional limit of API requests per second. Example: 2.5",
+				},
+			},
+		},
+		{
+			Name:   "list",
+			Usage:  "List the configured profiles",
+			Action: profileList,
+		},
+		{
+			Name:      "use",
+			Usage:     "Select the profile used when --profile is not given",
+			ArgsUsage: "NAME",
+			Action:    profileUse,
+		},
+		{
+			Name:      "remove",
+			Usage:     "Remove a profile",
+			ArgsUsage: "NAME",
+			Action:    profileRemove,
+		},
+	},
+}
+
+// loadProfiles returns the configuration file path and content, exiting on failure.
+func loadProfiles(c *cli.Context) (string, *config.Config) {
+	path := bootstrap.ConfigPath(c)
+	cfg, err := config.Load(path)
+	if err != nil {
+		bootstrap.Fatal(err)
+	}
+	return path, cfg
+}
+
+func saveProfiles(path string, cfg *config.Config) {
+	if err := cfg.Save(path); err != nil {
+		bootstrap.Fatal(err)
+	}
+}
+
+// profileName returns the NAME argument of a profile command, exiting when it is missing.
+func profileName(c *cli.Context) string {
+	name := c.Args().First()
+	if name == "" {
+		bootstrap.Fatalf("The `profile %s` command requires a profile name.", c.Command.Name)
+	}
+	return name
+}
+
+func profileAdd(c *cli.Context) {
+	name := profileName(c)
+	if format := c.String("output"); format != "" {
+		if err := output.Validate(format); err != nil {
+			bootstrap.Fatalf("%s", err)
+		}
+	}
+	if c.Float64("max-rps") < 0 {
+		bootstrap.Fatalf("--max-rps must not be negative.")
+	}
+
+	path, cfg := loadProfiles(c)
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*config.Profile{}
+	}
+	profile, ok := cfg.Profiles[name]
+	if !ok {
+		profile = &config.Profile{}
+		cfg.Profiles[name] = profile
+	}
+
+	profile.Merge(config.Profile{
+		BaseURL:    c.String("baseurl"),
+		APIKey:     c.String("apikey"),
+		Username:   c.String("username"),
+		Password:   c.String("password"),
+		Subaccount: c.String("subaccount"),
+		Timezone:   c.String("timezone"),
+		Output:     c.String("output"),
+		MaxRPS:     c.Float64("max-rps"),
+	})
+
+	if cfg.Current == "" {
+		cfg.Current = name
+	}
+	saveProfiles(path, cfg)
+
+	if ok {
+		fmt.Printf("Updated profile '%s' in %s\n", name, path)
+	} else {
+		fmt.Printf("Added profile '%s' to %s\n", name, path)
+	}
+}
+
+func profileList(c *cli.Context) {
+	_, cfg := loadProfiles(c)
+
+	// The format is not resolved through the profiles, so that they can be
+	// listed when --profile names one that does not exist.
+	format := c.GlobalString("output")
+	if format == "" {
+		format = output.Table
+	}
+	out, err := output.New(os.Stdout, format, []string{"current", "name", "base_url", "auth", "subaccount", "timezone", "output", "max_rps"})
+	if err != nil {
+		bootstrap.Fatalf("%s", err)
+	}
+	for _, name := range cfg.Names() {
+		p := cfg.Profiles[name]
+
+		baseURL := p.BaseURL
+		if baseURL == "" {
+			baseURL = bootstrap.DefaultBaseURL
+		}
+
+		if err := out.Write(name == cfg.Current, name, baseURL, profileAuth(p), p.Subaccount, p.Timezone, p.Output, profileRate(p)); err != nil {
+			bootstrap.Fatal(err)
+		}
+	}
+	closeOutput(out)
+}
+
+// profileAuth describes the credentials of p without revealing them.
+func profileAuth(p *config.Profile) string {
+	switch {
+	case p.APIKey != "":
+		if len(p.APIKey) > 4 {
+			return "api key ..." + p.APIKey[len(p.APIKey)-4:]
+		}
+		return "api key"
+	case p.Username != "":
+		return "user " + p.Username
+	}
+	return "-"
+}
+
+// profileRate returns the rate limit of p, or "-" when unlimited.
+func profileRate(p *config.Profile) string {
+	if p.MaxRPS <= 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(p.MaxRPS, 'f', -1, 64)
+}
+
+func profileUse(c *cli.Context) {
+	name := profileName(c)
+	path, cfg := loadProfiles(c)
+
+	if _, ok := cfg.Profiles[name]; !ok {
+		bootstrap.Fatalf("Profile '%s' not found in %s.", name, path)
+	}
+
+	cfg.Current = name
+	saveProfiles(path, cfg)
+	fmt.Printf("Using profile '%s'\n", name)
+}
+
+func profileRemove(c *cli.Context) {
+	name := profileName(c)
+	path, cfg := loadProfiles(c)
+
+	if _, ok := cfg.Profiles[name]; !ok {
+		bootstrap.Fatalf("Profile '%s' not found in %s.", name, path)
+	}
+
+	delete(cfg.Profiles, name)
+	if cfg.Current == name {
+		cfg.Current = ""
+	}
+	saveProfiles(path, cfg)
+	fmt.Printf("Removed profile '%s'\n", name)
+}
@@ -0,0 +1,374 @@
+package commands
+
+import (
+	"log"
+	"strings"
+
+	sp "github.com/SparkPost/gosparkpost"
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/bootstrap"
+	"github.com/SparkPost/sparkpost-cli/internal/dedup"
+	"github.com/SparkPost/sparkpost-cli/internal/importer"
+	"github.com/SparkPost/sparkpost-cli/internal/output"
+)
+
+var suppressionSearchParameters = []string{
+	"to", "from", "domain", "cursor", "limit", "per_page", "page", "sources", "types", "description",
+}
+
+var suppressionSearchFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "from",
+		Value: "",
+		Usage: "Optional datetime the entries were last updated, in the format of YYYY-MM-DDTHH:mm:ssZ (2015-04-10T00:00:00)",
+	},
+	cli.StringFlag{
+		Name:  "to",
+		Value: "",
+		Usage: "Optional datetime the entries were last updated, in the format YYYY-MM-DDTHH:mm:ssZ (2015-04-10T00:00:00)",
+	},
+	cli.StringFlag{
+		Name:  "types",
+		Value: "",
+		Usage: "Optional types of entries to include in the search, i.e. entries with \"transactional\" and/or \"non_transactional\" keys set to true",
+	},
+	cli.StringFlag{
+		Name:  "limit",
+		Value: "",
+		Usage: "Optional maximum number of results to return. Must be between 1 and 100000. Default value is 100000",
+	},
+	cli.StringFlag{
+		Name:  "page",
+		Value: "",
+		Usage: "Optional results page number to return. Used with per_page for paging through result. Example: 25. Default: 1",
+	},
+	cli.StringFlag{
+		Name:  "per_page",
+		Value: "",
+		Usage: "Optional number of results to return per page. Must be between 1 and 10,000 (inclusive). Example: 100. Default: 1000.",
+	},
+	cli.StringFlag{
+		Name:  "cursor",
+		Value: "",
+		Usage: "Optional the results cursor location to return, to start paging with cursor, use the value of ‘initial’. When cursor is provided the page parameter is ignored. ( Note: SparkPost only). Example initial",
+	},
+	cli.StringFlag{
+		Name:  "domain",
+		Value: "",
+		Usage: "Domain of entries to include in the search. ( Note: SparkPost only). Example yahoo.com",
+	},
+	cli.StringFlag{
+		Name:  "sources",
+		Value: "",
+		Usage: "Types of entries to include in the search, i.e. entries that are transactional or non_transactional",
+	},
+	cli.StringFlag{
+		Name:  "description",
+		Value: "",
+		Usage: "Description of the entries to include in the search, i.e descriptions that include the text submitted. ( Note: SparkPost only)",
+	},
+}
+
+var recipientFlag = cli.StringFlag{
+	Name:  "recipient",
+	Value: "",
+	Usage: "Recipient email address. Example rcpt_1@example.com",
+}
+
+var blacklistFileFlag = cli.StringSliceFlag{
+	Name:  "file, f",
+	Value: &cli.StringSlice{},
+	Usage: "Compatible blacklist file, repeat the flag or use a glob pattern to import several files, - for the standard input. Gzip files and zip archives are decompressed. See README.md for more info.",
+}
+
+var descriptionFlag = cli.StringFlag{
+	Name:  "description",
+	Value: "Imported from CSV",
+	Usage: "Description of the imported entries when no description column is mapped",
+}
+
+var mailgunKindFlag = cli.StringFlag{
+	Name:  "kind",
+	Value: "",
+	Usage: "Optional kind of the exported suppressions, one of " + strings.Join(importer.MailgunKinds, ", ") + ". Recognised from the fields of each entry by default",
+}
+
+var importFlags = []cli.Flag{
+	blacklistFileFlag,
+	cli.StringFlag{
+		Name:  "map",
+		Value: "",
+		Usage: "Optional column mapping overriding the default of the command. Columns are given by index (from 0) or header name. Example: email=0,type=reason,description=detail",
+	},
+	cli.StringFlag{
+		Name:  "type",
+		Value: "",
+		Usage: "Optional type of every imported entry: transactional, non_transactional or both. Default: derived from the reason of each row, see --type-rules",
+	},
+	cli.StringFlag{
+		Name:  "type-rules",
+		Value: "",
+		Usage: "Optional types overriding the default of some reasons. Default: hard_bounce=both,spam_complaint=both,blocked=both,unsubscribe=non_transactional,manual=non_transactional",
+	},
+	cli.BoolFlag{
+		Name:  "reject-disposable",
+		Usage: "Skip the addresses of disposable address providers such as mailinator.com",
+	},
+	cli.BoolFlag{
+		Name:  "reject-role",
+		Usage: "Skip role addresses such as postmaster@ or info@",
+	},
+	cli.IntFlag{
+		Name:  "dedup-memory",
+		Value: dedup.DefaultLimit,
+		Usage: "Number of recipients kept in memory to skip duplicates, beyond which they are kept in a temporary file",
+	},
+	cli.BoolFlag{
+		Name:  "resume",
+		Usage: "Continue an interrupted import after the last batch recorded in its checkpoint",
+	},
+	cli.StringFlag{
+		Name:  "checkpoint",
+		Value: "",
+		Usage: "Optional path of the checkpoint recording the progress of the import of a single file. Default: the file path followed by .checkpoint",
+	},
+	cli.IntFlag{
+		Name:  "batch-size",
+		Value: importBatchSize,
+		Usage: "Number of entries uploaded per request",
+	},
+	cli.IntFlag{
+		Name:  "concurrency",
+		Value: 1,
+		Usage: "Number of batches uploaded in parallel",
+	},
+	cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Apply every filter and print a report of the rows that would be imported, without uploading anything",
+	},
+	cli.StringFlag{
+		Name:  "rejects",
+		Value: "",
+		Usage: "Optional path of a CSV file receiving the rows that are not imported, with their row number and reason",
+	},
+}
+
+var suppressionCommand = cli.Command{
+	Name:  "suppression",
+	Usage: "Manage the suppression list\n\n\tSee https://developers.sparkpost.com/api/suppression-list.html",
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "Lists the entries in the SparkPost suppression list",
+			Flags:  suppressionSearchFlags,
+			Action: suppressionSearch,
+		},
+		{
+			Name:   "search",
+			Usage:  "Perform a filtered search for entries in your customer-specific exclusion list",
+			Flags:  suppressionSearchFlags,
+			Action: suppressionSearch,
+		},
+		{
+			Name:   "retrieve",
+			Usage:  "Retrieve the suppression status for a specific recipient",
+			Flags:  []cli.Flag{recipientFlag},
+			Action: suppressionRetrieve,
+		},
+		{
+			Name:   "stats",
+			Usage:  "Count the entries of the suppression list by type, source, domain, description prefix and day",
+			Flags:  statsFlags,
+			Action: suppressionStatistics,
+		},
+		{
+			Name:   "check",
+			Usage:  "Tell whether the recipients given as arguments or in a CSV file are suppressed, from the API or the local cache with --offline",
+			Flags:  checkFlags,
+			Action: suppressionCheck,
+		},
+		cacheCommand,
+		{
+			Name:   "add",
+			Usage:  "Add or update the suppression list entries of a recipient or of the recipients of a file",
+			Flags:  addFlags,
+			Action: suppressionAdd,
+		},
+		{
+			Name:   "delete",
+			Usage:  "Delete the suppression list entry for a specific recipient, the recipients of a file or the entries matching a search",
+			Flags:  deleteFlags,
+			Action: suppressionDelete,
+		},
+		{
+			Name:   "export",
+			Usage:  "Write every entry of the suppression list to a backup file, with a manifest of its counts and checksums",
+			Flags:  exportFlags,
+			Action: suppressionExport,
+		},
+		{
+			Name:   "restore",
+			Usage:  "Upload the entries of a backup written by export, keeping their types and descriptions",
+			Flags:  restoreFlags,
+			Action: suppressionRestore,
+		},
+		{
+			Name:   "diff",
+			Usage:  "Compare a file with the suppression list and list the entries to add, update or remove",
+			Flags:  diffFlags,
+			Action: suppressionDiff,
+		},
+		{
+			Name:   "sync",
+			Usage:  "Make the suppression list match a file, adding, updating and deleting entries",
+			Flags:  syncFlags,
+			Action: suppressionSync,
+		},
+		{
+			Name:   "mandrill",
+			Usage:  "Import a Mandrill blacklist CSV. See https://mandrill.zendesk.com/hc/en-us/articles/205582997",
+			Flags:  importFlags,
+			Action: suppressionMandrill,
+		},
+		{
+			Name:   "sendgrid",
+			Usage:  "Import a SendGrid suppression CSV. See sendgrid-suppressions.md",
+			Flags:  importFlags,
+			Action: suppressionSendgrid,
+		},
+		{
+			Name:   "mailgun",
+			Usage:  "Import Mailgun bounces, unsubscribes or complaints, exported as CSV or JSON",
+			Flags:  append([]cli.Flag{mailgunKindFlag}, importFlags...),
+			Action: suppressionMailgun,
+		},
+		{
+			Name:   "ses",
+			Usage:  "Import the Amazon SES account-level suppression list, as listed by `aws sesv2 list-suppressed-destinations`",
+			Flags:  importFlags,
+			Action: suppressionSES,
+		},
+		{
+			Name:   "postmark",
+			Usage:  "Import the suppressions of a Postmark message stream, as returned by its suppressions API",
+			Flags:  importFlags,
+			Action: suppressionPostmark,
+		},
+		{
+			Name:   "mailjet",
+			Usage:  "Import the blocked and unsubscribed contacts of a Mailjet contacts CSV export",
+			Flags:  importFlags,
+			Action: suppressionMailjet,
+		},
+		{
+			Name:   "mailchimp",
+			Usage:  "Import the cleaned and unsubscribed members of a Mailchimp audience export",
+			Flags:  importFlags,
+			Action: suppressionMailchimp,
+		},
+		{
+			Name:   "import",
+			Usage:  "Import any CSV file, with the columns given by --map",
+			Flags:  append([]cli.Flag{descriptionFlag}, importFlags...),
+			Action: suppressionImport,
+		},
+	},
+}
+
+func suppressionSearch(c *cli.Context) {
+	client := bootstrap.Client(c)
+
+	parameters := collectParameters(c, suppressionSearchParameters)
+
+	out := newOutput(c, output.CSV, suppressionColumns...)
+	defer closeOutput(out)
+
+	err := eachSuppressionPage(c, client, parameters, func(suppressionPage *sp.SuppressionPage) error {
+		writeSuppressionPage(out, suppressionPage)
+		return nil
+	})
+	if err != nil {
+		bootstrap.Fatal(err)
+	}
+}
+
+// eachSuppressionPage searches the suppression list with parameters and calls
+// fn with every page of results, following the cursor from one page to the
+// next. When parameters request a specific page only that page is read.
+func eachSuppressionPage(c *cli.Context, client *sp.Client, parameters map[string]string, fn func(*sp.SuppressionPage) error) error {
+	if _, ok := parameters["cursor"]; !ok {
+		parameters["cursor"] = "initial"
+	}
+
+	suppressionPage := &sp.SuppressionPage{Params: parameters}
+	if err := bootstrap.Check(client.SuppressionSearch(suppressionPage)); err != nil {
+		return err
+	}
+
+	for {
+		if err := bootstrap.Errors(suppressionPage.Errors); err != nil {
+			return err
+		}
+
+		if err := fn(suppressionPage); err != nil {
+			return err
+		}
+
+		// If user requested a specific page don't page through rest of results
+		if parameters["page"] != "" {
+			return nil
+		}
+
+		if suppressionPage.NextPage == "" {
+			return nil
+		}
+
+		if bootstrap.Verbose(c) {
+			log.Printf("NextPage(): %s", suppressionPage.NextPage)
+		}
+		var err error
+		suppressionPage, _, err = suppressionPage.Next()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func suppressionRetrieve(c *cli.Context) {
+	recpipient := c.String("recipient")
+	if recpipient == "" {
+		bootstrap.Fatalf("The `retrieve` command requires a recipient.")
+		return
+	}
+
+	client := bootstrap.Client(c)
+
+	suppressionPage := &sp.SuppressionPage{}
+	if err := bootstrap.Check(client.SuppressionRetrieve(recpipient, suppressionPage)); err != nil {
+		bootstrap.Fatal(err)
+		return
+	}
+
+	out := newOutput(c, output.CSV, suppressionColumns...)
+	writeSuppressionPage(out, suppressionPage)
+	closeOutput(out)
+}
+
+// suppressionColumns names the fields written for each suppression list entry.
+var suppressionColumns = []string{
+	"recipient", "transactional", "non_transactional", "source", "updated", "created", "description",
+}
+
+// writeSuppressionPage writes every entry of suppressionPage to out.
+func writeSuppressionPage(out output.Writer, suppressionPage *sp.SuppressionPage) {
+	entries := suppressionPage.Results
+
+	for i := range entries {
+		entry := entries[i]
+		err := out.Write(entry.Recipient, entry.Transactional, entry.NonTransactional, entry.Source, entry.Updated, entry.Created, entry.Description)
+		if err != nil {
+			bootstrap.Fatal(err)
+		}
+	}
+}
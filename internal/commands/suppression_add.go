@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	sp "github.com/SparkPost/gosparkpost"
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/bootstrap"
+	"github.com/SparkPost/sparkpost-cli/internal/importer"
+	"github.com/SparkPost/sparkpost-cli/internal/output"
+	"github.com/SparkPost/sparkpost-cli/internal/validate"
+)
+
+// addBatchSize is the number of entries uploaded per request by `add`.
+const addBatchSize = 10000
+
+// Statuses of the entries given to `add`.
+const (
+	addNew       = "new"
+	addUpdated   = "updated"
+	addUnchanged = "unchanged"
+)
+
+var addFlags = append([]cli.Flag{
+	recipientFlag,
+	cli.StringFlag{
+		Name:  "file, f",
+		Value: "",
+		Usage: "File listing the recipients to add, one per line or in the column given by --map",
+	},
+	cli.StringFlag{
+		Name:  "map",
+		Value: "email=0",
+		Usage: "Column of the recipients in --file, by index (from 0) or header name",
+	},
+	cli.StringFlag{
+		Name:  "type",
+		Value: importer.DefaultTypeRules[importer.ReasonManual],
+		Usage: "Type of the entries: transactional, non_transactional or both",
+	},
+	cli.StringFlag{
+		Name:  "description",
+		Value: "",
+		Usage: "Optional description of the entries. Existing entries keep theirs when not given",
+	},
+	cli.IntFlag{
+		Name:  "concurrency",
+		Value: 4,
+		Usage: "Number of recipients looked up in parallel",
+	},
+}, pickFlags(importFlags, "reject-disposable", "reject-role")...)
+
+// suppressionAdd adds the `--recipient` or the recipients of `--file` to the
+// suppression list. Every recipient is looked up first, so that entries that
+// already exist with the same description are left alone and the others are
+// reported as new or updated.
+func suppressionAdd(c *cli.Context) {
+	recipient, file := c.String("recipient"), c.String("file")
+	if (recipient == "") == (file == "") {
+		bootstrap.Fatalf("The `add` command requires either a recipient or a file.")
+		return
+	}
+
+	typ, err := importer.ParseType(c.String("type"))
+	if err != nil {
+		bootstrap.Fatalf("%s", err)
+		return
+	}
+	validation := validate.Options{
+		Disposable: c.Bool("reject-disposable"),
+		Role:       c.Bool("reject-role"),
+	}
+
+	var recipients []string
+	if recipient != "" {
+		normalized, err := validate.Normalize(recipient)
+		if err == nil {
+			err = validation.Check(normalized)
+		}
+		if err != nil {
+			bootstrap.Fatalf("'%s' cannot be added. %s.", recipient, validate.Describe(err.(*validate.Error).Reason))
+			return
+		}
+		recipients = []string{normalized}
+	} else if recipients, err = fileRecipients(file, c.String("map"), &validation); err != nil {
+		bootstrap.Fatal(err)
+		return
+	}
+	if len(recipients) == 0 {
+		fmt.Println("No recipients to add.")
+		return
+	}
+
+	client := bootstrap.Client(c)
+
+	description := c.String("description")
+	existing, errs := retrieveRecipients(client, c.Int("concurrency"), recipients)
+
+	var failed int
+	var upserts []sp.WritableSuppressionEntry
+	var statuses []string
+	var entries []sp.WritableSuppressionEntry
+	for i, recipient := range recipients {
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: Failed to look up '%s': %s\n", recipient, errs[i])
+			failed++
+			continue
+		}
+		for _, entry := range importer.Expand(sp.WritableSuppressionEntry{Recipient: recipient, Type: typ, Description: description}) {
+			status := addStatus(entry, existing[i])
+			if status != addUnchanged {
+				upserts = append(upserts, entry)
+			}
+			entries = append(entries, entry)
+			statuses = append(statuses, status)
+		}
+	}
+
+	for start := 0; start < len(upserts); start += addBatchSize {
+		end := start + addBatchSize
+		if end > len(upserts) {
+			end = len(upserts)
+		}
+		if err := bootstrap.Check(client.SuppressionUpsert(upserts[start:end])); err != nil {
+			bootstrap.Fatal(err)
+			return
+		}
+	}
+
+	counts := map[string]int{}
+	out := newOutput(c, output.Table, "recipient", "type", "status")
+	for i, entry := range entries {
+		counts[statuses[i]]++
+		if err := out.Write(entry.Recipient, entry.Type, statuses[i]); err != nil {
+			bootstrap.Fatal(err)
+		}
+	}
+	closeOutput(out)
+	fmt.Fprintf(os.Stderr, "New: %d, updated: %d, unchanged: %d entries\n", counts[addNew], counts[addUpdated], counts[addUnchanged])
+
+	if failed > 0 {
+		bootstrap.Fatal(fmt.Errorf("%d of %d recipients could not be looked up", failed, len(recipients)))
+	}
+}
+
+// addStatus tells whether entry is new, updates one of the existing entries
+// of its recipient or is already there. An empty description matches any.
+func addStatus(entry sp.WritableSuppressionEntry, existing []sp.SuppressionEntry) string {
+	for _, e := range existing {
+		if !hasType(e, entry.Type) {
+			continue
+		}
+		if entry.Description == "" || entry.Description == e.Description {
+			return addUnchanged
+		}
+		return addUpdated
+	}
+	return addNew
+}
+
+// hasType reports whether e suppresses the messages of typ.
+func hasType(e sp.SuppressionEntry, typ string) bool {
+	switch typ {
+	case importer.Transactional:
+		return e.Transactional || e.Type == typ
+	case importer.NonTransactional:
+		return e.NonTransactional || e.Type == typ
+	}
+	return false
+}
+
+// retrieveRecipient returns the suppression list entries of recipient, none
+// when it is not suppressed.
+func retrieveRecipient(client *sp.Client, recipient string) ([]sp.SuppressionEntry, error) {
+	suppressionPage := &sp.SuppressionPage{}
+	res, err := client.SuppressionRetrieve(recipient, suppressionPage)
+	if res != nil && res.HTTP != nil && res.HTTP.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err := bootstrap.Check(res, err); err != nil {
+		return nil, err
+	}
+
+	var entries []sp.SuppressionEntry
+	for _, result := range suppressionPage.Results {
+		e := *result
+		if e.Recipient == "" {
+			e.Recipient = e.Email
+		}
+		if strings.EqualFold(e.Recipient, recipient) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// retrieveRecipients looks up recipients, concurrency at a time, and returns
+// their entries and the errors of the lookups, both in the order of
+// recipients. The requests are paced by the client, see `--max-rps`.
+func retrieveRecipients(client *sp.Client, concurrency int, recipients []string) ([][]sp.SuppressionEntry, []error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	entries := make([][]sp.SuppressionEntry, len(recipients))
+	errs := make([]error, len(recipients))
+
+	queue := make(chan int)
+	go func() {
+		for i := range recipients {
+			queue <- i
+		}
+		close(queue)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				entries[i], errs[i] = retrieveRecipient(client, recipients[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return entries, errs
+}
@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	sp "github.com/SparkPost/gosparkpost"
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/backup"
+	"github.com/SparkPost/sparkpost-cli/internal/bootstrap"
+	"github.com/SparkPost/sparkpost-cli/internal/importer"
+)
+
+// exportPageSize is the number of entries requested per page when exporting.
+const exportPageSize = "10000"
+
+// suppressionFilterParameters are the search parameters selecting the entries
+// of a whole list operation, as opposed to a page of results.
+var suppressionFilterParameters = []string{
+	"to", "from", "domain", "sources", "types", "description",
+}
+
+var exportFlags = append([]cli.Flag{
+	cli.StringFlag{
+		Name:  "file, f",
+		Value: "",
+		Usage: "Path of the backup file. Entries are written one JSON object per line, gzip compressed when the path ends in .gz",
+	},
+	cli.BoolFlag{
+		Name:  "gzip",
+		Usage: "Compress the backup whatever its name",
+	},
+}, pickFlags(suppressionSearchFlags, suppressionFilterParameters...)...)
+
+var restoreFlags = append(pickFlags(importFlags,
+	"file", "resume", "checkpoint", "batch-size", "concurrency", "dry-run", "rejects"),
+	cli.BoolFlag{
+		Name:  "no-verify",
+		Usage: "Restore the backup even when it has no manifest or does not match it",
+	},
+)
+
+// pickFlags returns the flags named names, in the order of flags.
+func pickFlags(flags []cli.Flag, names ...string) []cli.Flag {
+	var picked []cli.Flag
+	for _, f := range flags {
+		name := strings.TrimSpace(strings.Split(f.GetName(), ",")[0])
+		for _, n := range names {
+			if n == name {
+				picked = append(picked, f)
+			}
+		}
+	}
+	return picked
+}
+
+// suppressionExport writes every entry of the suppression list, or those
+// matching the search flags, to a backup file and its manifest.
+func suppressionExport(c *cli.Context) {
+	file := c.String("file")
+	if file == "" {
+		bootstrap.Fatalf("The `export` command requires a file to write the backup to.")
+		return
+	}
+
+	client := bootstrap.Client(c)
+
+	parameters := collectParameters(c, suppressionFilterParameters)
+	filters := map[string]string{}
+	for k, v := range parameters {
+		filters[k] = v
+	}
+	parameters["per_page"] = exportPageSize
+
+	w, err := backup.Create(file, c.Bool("gzip") || backup.IsGzip(file), filters)
+	if err != nil {
+		bootstrap.Fatalf("Failed to create '%s': %s", file, err)
+		return
+	}
+
+	var count int64
+	err = eachSuppressionPage(c, client, parameters, func(suppressionPage *sp.SuppressionPage) error {
+		for _, entry := range suppressionPage.Results {
+			if err := w.Write(backup.NewEntry(entry)); err != nil {
+				return err
+			}
+		}
+		count += int64(len(suppressionPage.Results))
+		fmt.Printf("Exported %d entries\n", count)
+		return nil
+	})
+	if err != nil {
+		w.Remove()
+		bootstrap.Fatal(err)
+		return
+	}
+
+	manifest, err := w.Close()
+	if err != nil {
+		w.Remove()
+		bootstrap.Fatalf("Failed to write '%s': %s", file, err)
+		return
+	}
+
+	fmt.Printf("Wrote %d entries (%d transactional, %d non_transactional) to '%s', manifest '%s'\n",
+		manifest.Entries, manifest.Transactional, manifest.NonTransactional, file, backup.ManifestPath(file))
+	fmt.Println("DONE")
+}
+
+// suppressionRestore uploads the entries of a backup written by `export`,
+// after checking it against its manifest.
+func suppressionRestore(c *cli.Context) {
+	if !c.Bool("no-verify") {
+		inputs, err := importer.ExpandInputs(c.StringSlice("file"))
+		if err != nil {
+			bootstrap.Fatalf("%s", err)
+			return
+		}
+		for _, in := range inputs {
+			if in.Path == importer.Stdin || in.Member != "" {
+				bootstrap.Fatalf("'%s' has no manifest to be verified against. Use --no-verify to restore it anyway.", in)
+				return
+			}
+			verifyBackup(in.Path)
+		}
+	}
+
+	importSuppressions(c, importer.Backup)
+}
+
+// verifyBackup exits unless the backup at file matches its manifest.
+func verifyBackup(file string) {
+	manifest, err := backup.LoadManifest(file)
+	if err != nil {
+		bootstrap.Fatal(err)
+		return
+	}
+	if manifest == nil {
+		bootstrap.Fatalf("No manifest found for '%s' at '%s'. Use --no-verify to restore it anyway.", file, backup.ManifestPath(file))
+		return
+	}
+
+	if err := manifest.Verify(file); err != nil {
+		bootstrap.Fatalf("%s. Use --no-verify to restore it anyway.", err)
+		return
+	}
+	fmt.Printf("Verified '%s': %d entries exported on %s\n", file, manifest.Entries, manifest.Date.Format("2006-01-02T15:04:05Z"))
+}
@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	sp "github.com/SparkPost/gosparkpost"
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/backup"
+	"github.com/SparkPost/sparkpost-cli/internal/bootstrap"
+	"github.com/SparkPost/sparkpost-cli/internal/cache"
+)
+
+// searchTime is the format of the `from` and `to` search parameters.
+const searchTime = "2006-01-02T15:04:05"
+
+// syncOverlap is how far before the previous sync an incremental sync
+// starts, so that entries updated while it ran are not missed.
+const syncOverlap = time.Hour
+
+// staleCache is the age beyond which `check --offline` warns about the cache.
+const staleCache = 24 * time.Hour
+
+var cacheFlag = cli.StringFlag{
+	Name:  "cache",
+	Value: "",
+	Usage: "Optional path of the local cache of the suppression list. Default: " + cache.DefaultPath(),
+}
+
+var cacheCommand = cli.Command{
+	Name:  "cache",
+	Usage: "Keep a local copy of the suppression list for offline lookups",
+	Subcommands: []cli.Command{
+		{
+			Name:  "sync",
+			Usage: "Download the entries updated since the last sync, or the whole list the first time",
+			Flags: []cli.Flag{
+				cacheFlag,
+				cli.BoolFlag{
+					Name:  "full",
+					Usage: "Download the whole list again, dropping the entries deleted since",
+				},
+			},
+			Action: suppressionCacheSync,
+		},
+	},
+}
+
+// cachePath returns the cache selected by `--cache`, or the default location.
+func cachePath(c *cli.Context) string {
+	if path := c.String("cache"); path != "" {
+		return path
+	}
+	return cache.DefaultPath()
+}
+
+// accountFingerprint identifies the credentials of o without revealing them.
+func accountFingerprint(o bootstrap.Options) string {
+	credentials := o.APIKey
+	if credentials == "" {
+		credentials = "user:" + o.Username
+	}
+	sum := sha256.Sum256([]byte(credentials))
+	return hex.EncodeToString(sum[:6])
+}
+
+// suppressionCacheSync refreshes the cache with the entries updated since
+// its last sync, or downloads the whole list into a new cache the first time,
+// with `--full` or when the cache holds the list of another account. An
+// incremental sync cannot tell which entries were deleted in the meantime.
+func suppressionCacheSync(c *cli.Context) {
+	path := cachePath(c)
+	settings := bootstrap.Settings(c)
+	client := bootstrap.ClientFor(settings)
+	account := accountFingerprint(settings)
+	now := time.Now().UTC()
+
+	meta, err := readCacheMeta(path)
+	if err != nil {
+		bootstrap.Fatal(err)
+		return
+	}
+	if meta != nil && !c.Bool("full") && (meta.BaseURL != settings.BaseURL || meta.Subaccount != settings.Subaccount || meta.Account != account) {
+		bootstrap.Fatalf("Cache '%s' holds the suppression list of another account or subaccount of %s. Use --full to replace it, or --cache to keep one cache per account.", path, meta.BaseURL)
+		return
+	}
+
+	parameters := map[string]string{"per_page": exportPageSize}
+	target := path
+	if meta == nil || c.Bool("full") {
+		// The new list is written aside, so the cache stays usable until it is complete.
+		target = path + ".tmp"
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			bootstrap.Fatal(err)
+			return
+		}
+		meta = &cache.Meta{
+			BaseURL:    settings.BaseURL,
+			Subaccount: settings.Subaccount,
+			Account:    account,
+			FullSync:   now,
+		}
+	} else {
+		parameters["from"] = meta.Synced.Add(-syncOverlap).Format(searchTime)
+		parameters["to"] = now.Format(searchTime)
+	}
+
+	cch, err := cache.Open(target, false)
+	if err != nil {
+		bootstrap.Fatal(err)
+		return
+	}
+
+	var count int64
+	err = eachSuppressionPage(c, client, parameters, func(suppressionPage *sp.SuppressionPage) error {
+		entries := make([]backup.Entry, len(suppressionPage.Results))
+		for i, entry := range suppressionPage.Results {
+			entries[i] = backup.NewEntry(entry)
+		}
+		if err := cch.Put(entries); err != nil {
+			return err
+		}
+		count += int64(len(entries))
+		fmt.Printf("Fetched %d entries\n", count)
+		return nil
+	})
+	if err == nil {
+		meta.Synced = now
+		err = cch.SetMeta(meta)
+	}
+	if closeErr := cch.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && target != path {
+		err = os.Rename(target, path)
+	}
+	if err != nil {
+		if target != path {
+			os.Remove(target)
+		}
+		bootstrap.Fatal(err)
+		return
+	}
+
+	if from, ok := parameters["from"]; ok {
+		fmt.Printf("Refreshed %d entries updated since %s, %d recipients cached in '%s'\n", count, from, meta.Entries, path)
+		return
+	}
+	fmt.Printf("Downloaded %d entries, %d recipients cached in '%s'\n", count, meta.Entries, path)
+}
+
+// readCacheMeta returns the description of the cache at path, nil when there
+// is none or it was never synced.
+func readCacheMeta(path string) (*cache.Meta, error) {
+	cch, err := cache.Open(path, true)
+	if err != nil || cch == nil {
+		return nil, err
+	}
+	defer cch.Close()
+	return cch.Meta()
+}
+
+// lookupCache returns the entries cached for each of recipients, in the same
+// order, after telling how old the cache is.
+func lookupCache(c *cli.Context, recipients []string) [][]backup.Entry {
+	path := cachePath(c)
+	cch, err := cache.Open(path, true)
+	if err != nil {
+		bootstrap.Fatal(err)
+	}
+	if cch == nil {
+		bootstrap.Fatalf("No cache found at '%s'. Run `sparkpost suppression cache sync` first.", path)
+	}
+	defer cch.Close()
+
+	meta, err := cch.Meta()
+	if err != nil {
+		bootstrap.Fatal(err)
+	}
+	if meta == nil {
+		bootstrap.Fatalf("Cache '%s' was never synced. Run `sparkpost suppression cache sync` first.", path)
+	}
+	reportCacheAge(meta, time.Now().UTC())
+
+	found, err := cch.Lookup(recipients)
+	if err != nil {
+		bootstrap.Fatal(err)
+	}
+	return found
+}
+
+// reportCacheAge tells on stderr how old the cache described by meta is.
+func reportCacheAge(meta *cache.Meta, now time.Time) {
+	synced := now.Sub(meta.Synced).Truncate(time.Second)
+	fmt.Fprintf(os.Stderr, "Cache of %s: %d recipients, synced %s ago (%s), fully downloaded %s ago\n",
+		meta.BaseURL, meta.Entries, synced, meta.Synced.Format(time.RFC3339), now.Sub(meta.FullSync).Truncate(time.Second))
+	if synced > staleCache {
+		fmt.Fprintf(os.Stderr, "WARN: The cache is more than %s old, run `sparkpost suppression cache sync` to refresh it.\n", staleCache)
+	}
+}
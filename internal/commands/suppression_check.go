@@ -0,0 +1,266 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	sp "github.com/SparkPost/gosparkpost"
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/backup"
+	"github.com/SparkPost/sparkpost-cli/internal/bootstrap"
+	"github.com/SparkPost/sparkpost-cli/internal/importer"
+	"github.com/SparkPost/sparkpost-cli/internal/output"
+	"github.com/SparkPost/sparkpost-cli/internal/validate"
+)
+
+var checkFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "file, f",
+		Value: "",
+		Usage: "CSV file listing the recipients to check, one per line or in the column given by --map, - for the standard input. The report repeats its columns",
+	},
+	cli.StringFlag{
+		Name:  "map",
+		Value: "email=0",
+		Usage: "Column of the recipients in --file, by index (from 0) or header name, for example email=Email",
+	},
+	cli.BoolFlag{
+		Name:  "header",
+		Usage: "The first row of --file is a header row naming the columns. Otherwise it is only recognised when --map names a column or the recipient column is headed email, Email Address, recipient or address",
+	},
+	cli.IntFlag{
+		Name:  "concurrency",
+		Value: 4,
+		Usage: "Number of recipients looked up in parallel",
+	},
+	cli.BoolFlag{
+		Name:  "offline",
+		Usage: "Answer from the local cache written by `suppression cache sync` instead of the API",
+	},
+	cacheFlag,
+}
+
+// checkColumns names the fields written for each recipient checked.
+var checkColumns = []string{
+	"recipient", "suppressed", "transactional", "non_transactional", "source", "updated", "description",
+}
+
+// checkInput holds the records to check, each with its normalized recipient,
+// empty when the record has no valid address.
+type checkInput struct {
+	columns    []string
+	records    [][]string
+	recipients []string
+}
+
+// suppressionCheck tells whether each recipient given as argument or listed
+// in `--file` is suppressed, looking them up concurrently through the API or
+// in the cache with `--offline`. The report of a file repeats its columns,
+// followed by the status of the recipient of each row.
+func suppressionCheck(c *cli.Context) {
+	file := c.String("file")
+	if (len(c.Args()) == 0) == (file == "") {
+		bootstrap.Fatalf("The `check` command requires either recipients as arguments or a file.")
+		return
+	}
+
+	var input *checkInput
+	if file != "" {
+		input = readCheckFile(file, c.String("map"), c.Bool("header"))
+	} else {
+		input = readCheckArgs(c.Args())
+	}
+
+	var unique []string
+	seen := map[string]bool{}
+	for _, recipient := range input.recipients {
+		if key := strings.ToLower(recipient); recipient != "" && !seen[key] {
+			seen[key] = true
+			unique = append(unique, recipient)
+		}
+	}
+
+	var found [][]backup.Entry
+	errs := make([]error, len(unique))
+	if c.Bool("offline") {
+		found = lookupCache(c, unique)
+	} else {
+		var entries [][]sp.SuppressionEntry
+		entries, errs = retrieveRecipients(bootstrap.Client(c), c.Int("concurrency"), unique)
+		found = make([][]backup.Entry, len(unique))
+		for i := range entries {
+			for j := range entries[i] {
+				found[i] = append(found[i], backup.NewEntry(&entries[i][j]))
+			}
+		}
+	}
+
+	results := map[string][]backup.Entry{}
+	failed := map[string]bool{}
+	for i, recipient := range unique {
+		key := strings.ToLower(recipient)
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "ERROR: Failed to look up '%s': %s\n", recipient, errs[i])
+			failed[key] = true
+			continue
+		}
+		results[key] = found[i]
+	}
+
+	columns := append(append([]string{}, input.columns...), joinedColumns(input.columns, checkColumns[1:])...)
+	out := newOutput(c, output.Table, columns...)
+	for i, record := range input.records {
+		values := make([]interface{}, 0, len(columns))
+		for j := range input.columns {
+			value := ""
+			if j < len(record) {
+				value = record[j]
+			}
+			values = append(values, value)
+		}
+
+		key := strings.ToLower(input.recipients[i])
+		if key == "" || failed[key] {
+			for range checkColumns[1:] {
+				values = append(values, "")
+			}
+		} else {
+			values = append(values, checkValues(results[key])...)
+		}
+
+		if err := out.Write(values...); err != nil {
+			bootstrap.Fatal(err)
+		}
+	}
+	closeOutput(out)
+
+	if len(failed) > 0 {
+		bootstrap.Fatal(fmt.Errorf("%d of %d recipients could not be looked up", len(failed), len(unique)))
+	}
+}
+
+// readCheckArgs returns the recipients given as arguments. Invalid addresses
+// are reported and checked as empty.
+func readCheckArgs(args []string) *checkInput {
+	input := &checkInput{columns: checkColumns[:1]}
+	for _, arg := range args {
+		normalized, err := validate.Normalize(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARN: Ignoring '%s'. %s.\n", arg, validate.Describe(err.(*validate.Error).Reason))
+		}
+		input.records = append(input.records, []string{arg})
+		input.recipients = append(input.recipients, normalized)
+	}
+	return input
+}
+
+// readCheckFile returns every row of the CSV file, with the recipient in the
+// column spec. The columns are named after the header row, if any, and
+// numbered otherwise. header tells that the first row is the header row.
+func readCheckFile(file, spec string, header bool) *checkInput {
+	mapping, err := importer.ParseMapping(spec)
+	if err != nil {
+		bootstrap.Fatalf("%s", err)
+	}
+
+	f, err := importer.Input{Path: file}.Open()
+	if err != nil {
+		bootstrap.Fatalf("%s", err)
+	}
+	defer f.Close()
+
+	rows, err := importer.NewCSV(f, importer.Position{}, mapping, importer.CSVOptions{FieldsPerRecord: -1, Header: header})
+	if err != nil {
+		bootstrap.Fatalf("Failed to process '%s': %s", file, err)
+	}
+
+	input := &checkInput{}
+	var names []string
+	width := 0
+	for {
+		row, err := rows.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			bootstrap.Fatalf("Failed to process '%s': %s", file, err)
+		}
+
+		if row.Skip == importer.SkipHeader {
+			if names == nil {
+				names = row.Record
+			}
+			continue
+		}
+		if row.Skip != "" {
+			warnRejected(row)
+			row.Entry.Recipient = ""
+		}
+		if len(row.Record) > width {
+			width = len(row.Record)
+		}
+		input.records = append(input.records, row.Record)
+		input.recipients = append(input.recipients, row.Entry.Recipient)
+	}
+
+	for i := 0; i < width || i < len(names); i++ {
+		name := ""
+		if i < len(names) {
+			name = strings.TrimSpace(names[i])
+		}
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		input.columns = append(input.columns, name)
+	}
+	return input
+}
+
+// joinedColumns returns names, prefixed with `suppression_` when they are
+// already among the columns of the input.
+func joinedColumns(input, names []string) []string {
+	joined := make([]string, len(names))
+	for i, name := range names {
+		joined[i] = name
+		for _, column := range input {
+			if strings.EqualFold(column, name) {
+				joined[i] = "suppression_" + name
+				break
+			}
+		}
+	}
+	return joined
+}
+
+// checkValues returns whether a recipient is suppressed according to its
+// entries, merging their flags, sources, update times and descriptions.
+func checkValues(entries []backup.Entry) []interface{} {
+	var transactional, nonTransactional bool
+	var sources, updated, descriptions []string
+	for _, e := range entries {
+		transactional = transactional || e.Transactional || e.Type == importer.Transactional
+		nonTransactional = nonTransactional || e.NonTransactional || e.Type == importer.NonTransactional
+		sources = appendDistinct(sources, e.Source)
+		updated = appendDistinct(updated, e.Updated)
+		descriptions = appendDistinct(descriptions, e.Description)
+	}
+	return []interface{}{
+		len(entries) > 0, transactional, nonTransactional,
+		strings.Join(sources, "; "), strings.Join(updated, "; "), strings.Join(descriptions, "; "),
+	}
+}
+
+// appendDistinct appends value to values unless empty or already there.
+func appendDistinct(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
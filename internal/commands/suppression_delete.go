@@ -0,0 +1,325 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sp "github.com/SparkPost/gosparkpost"
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/bootstrap"
+	"github.com/SparkPost/sparkpost-cli/internal/importer"
+	"github.com/SparkPost/sparkpost-cli/internal/validate"
+)
+
+// deleteSampleSize is the number of recipients shown before asking for confirmation.
+const deleteSampleSize = 10
+
+var deleteFlags = append(append([]cli.Flag{
+	recipientFlag,
+	cli.StringFlag{
+		Name:  "file, f",
+		Value: "",
+		Usage: "File listing the recipients to delete, one per line or in the column given by --map",
+	},
+	cli.StringFlag{
+		Name:  "map",
+		Value: "email=0",
+		Usage: "Column of the recipients in --file, by index (from 0) or header name",
+	},
+	cli.BoolFlag{
+		Name:  "search",
+		Usage: "Delete every entry matching the search flags",
+	},
+}, pickFlags(suppressionSearchFlags, suppressionFilterParameters...)...),
+	cli.BoolFlag{
+		Name:  "yes, y",
+		Usage: "Delete without asking for confirmation",
+	},
+	cli.IntFlag{
+		Name:  "concurrency",
+		Value: 4,
+		Usage: "Number of recipients deleted in parallel",
+	},
+	cli.StringFlag{
+		Name:  "failures",
+		Value: "",
+		Usage: "Optional path of a CSV file receiving the recipients that could not be deleted, with the error",
+	},
+)
+
+func suppressionDelete(c *cli.Context) {
+	if c.String("file") != "" || c.Bool("search") {
+		suppressionBulkDelete(c)
+		return
+	}
+
+	recpipient := c.String("recipient")
+	if recpipient == "" {
+		bootstrap.Fatalf("The `delete` command requires a recipient, a file or --search.")
+		return
+	}
+
+	client := bootstrap.Client(c)
+
+	if err := bootstrap.Check(client.SuppressionDelete(recpipient)); err != nil {
+		bootstrap.Fatal(err)
+		return
+	}
+	fmt.Println("OK")
+}
+
+// suppressionBulkDelete deletes the recipients listed in `--file` or found by
+// `--search`, once confirmed.
+func suppressionBulkDelete(c *cli.Context) {
+	if c.String("file") != "" && c.Bool("search") {
+		bootstrap.Fatalf("Use either --file or --search, not both.")
+		return
+	}
+
+	client := bootstrap.Client(c)
+
+	var recipients []string
+	var err error
+	if c.Bool("search") {
+		recipients, err = searchRecipients(c, client)
+	} else {
+		// Entries left behind by a bad import may not be valid addresses,
+		// they are deleted as listed.
+		recipients, err = fileRecipients(c.String("file"), c.String("map"), nil)
+	}
+	if err != nil {
+		bootstrap.Fatal(err)
+		return
+	}
+
+	if len(recipients) == 0 {
+		fmt.Println("No recipients to delete.")
+		return
+	}
+
+	fmt.Printf("%d recipients will be deleted from the suppression list, for example:\n", len(recipients))
+	for i := 0; i < len(recipients) && i < deleteSampleSize; i++ {
+		fmt.Printf("  %s\n", recipients[i])
+	}
+	if !c.Bool("yes") && !confirm("Delete them?") {
+		fmt.Println("Nothing was deleted.")
+		return
+	}
+
+	queue := make(chan string)
+	go func() {
+		for _, recipient := range recipients {
+			queue <- recipient
+		}
+		close(queue)
+	}()
+	failures := deleteRecipients(client, c.Int("concurrency"), len(recipients), queue)
+
+	if len(failures) > 0 {
+		reportDeleteFailures(c.String("failures"), failures)
+		bootstrap.Fatal(fmt.Errorf("%d of %d recipients could not be deleted", len(failures), len(recipients)))
+		return
+	}
+	fmt.Println("DONE")
+}
+
+// searchRecipients returns the recipients of the entries matching the search flags.
+func searchRecipients(c *cli.Context, client *sp.Client) ([]string, error) {
+	parameters := collectParameters(c, suppressionFilterParameters)
+	if len(parameters) == 0 {
+		return nil, fmt.Errorf("--search requires at least one of --%s, to avoid deleting the whole list", strings.Join(suppressionFilterParameters, ", --"))
+	}
+	parameters["per_page"] = exportPageSize
+
+	var recipients []string
+	seen := map[string]bool{}
+	err := eachSuppressionPage(c, client, parameters, func(suppressionPage *sp.SuppressionPage) error {
+		for _, entry := range suppressionPage.Results {
+			recipient := entry.Recipient
+			if recipient == "" {
+				recipient = entry.Email
+			}
+			if key := strings.ToLower(recipient); !seen[key] {
+				seen[key] = true
+				recipients = append(recipients, recipient)
+			}
+		}
+		return nil
+	})
+	return recipients, err
+}
+
+// fileRecipients returns the recipients of the column spec of file, skipping
+// its header row, empty values and duplicates. Recipients are normalized and
+// those rejected under validation are skipped, unless validation is nil: they
+// are then returned as listed, with a warning for invalid addresses.
+func fileRecipients(file, spec string, validation *validate.Options) ([]string, error) {
+	mapping, err := importer.ParseMapping(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := importer.NewCSV(f, importer.Position{}, mapping, importer.CSVOptions{FieldsPerRecord: -1, Verbatim: validation == nil})
+	if err != nil {
+		return nil, fmt.Errorf("failed to process '%s': %s", file, err)
+	}
+
+	var recipients []string
+	seen := map[string]bool{}
+	for {
+		row, err := rows.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to process '%s': %s", file, err)
+		}
+
+		switch {
+		case row.Skip != "":
+		case validation == nil:
+			if _, err := validate.Normalize(row.Entry.Recipient); err != nil {
+				fmt.Printf("WARN: Deleting '%s' as listed. %s.\n", row.Entry.Recipient, validate.Describe(err.(*validate.Error).Reason))
+			}
+		default:
+			if err := validation.Check(row.Entry.Recipient); err != nil {
+				row.Skip = err.(*validate.Error).Reason
+			}
+		}
+		if row.Skip != "" {
+			warnRejected(row)
+			continue
+		}
+		if key := strings.ToLower(row.Entry.Recipient); !seen[key] {
+			seen[key] = true
+			recipients = append(recipients, row.Entry.Recipient)
+		}
+	}
+	return recipients, nil
+}
+
+// confirm asks question on the terminal and reports whether it was answered yes.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// deleteFailure is a recipient that could not be deleted.
+type deleteFailure struct {
+	recipient string
+	err       error
+}
+
+// deleteRecipients deletes the recipients received from queue, concurrency at
+// a time, and returns those that failed. The requests are paced by the client,
+// see `--max-rps`. Progress is printed every few seconds against total, which
+// may be 0 when unknown.
+func deleteRecipients(client *sp.Client, concurrency, total int, queue <-chan string) []deleteFailure {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var deleted, failed int64
+	var mu sync.Mutex
+	var failures []deleteFailure
+
+	progress := func() {
+		d, f := atomic.LoadInt64(&deleted), atomic.LoadInt64(&failed)
+		if total > 0 {
+			fmt.Printf("Deleted %d of %d recipients, %d failed\n", d, total, f)
+		} else {
+			fmt.Printf("Deleted %d recipients, %d failed\n", d, f)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				progress()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for recipient := range queue {
+				if err := bootstrap.Check(client.SuppressionDelete(recipient)); err != nil {
+					atomic.AddInt64(&failed, 1)
+					mu.Lock()
+					failures = append(failures, deleteFailure{recipient, err})
+					mu.Unlock()
+					continue
+				}
+				atomic.AddInt64(&deleted, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+	progress()
+
+	return failures
+}
+
+// reportDeleteFailures prints failures, and writes them to the CSV file at path when given.
+func reportDeleteFailures(path string, failures []deleteFailure) {
+	for i, failure := range failures {
+		if path == "" || i < deleteSampleSize {
+			fmt.Printf("ERROR: Failed to delete '%s': %s\n", failure.recipient, failure.err)
+		}
+	}
+	if path == "" {
+		return
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		bootstrap.Fatalf("Failed to create '%s': %s", path, err)
+		return
+	}
+	w := csv.NewWriter(f)
+	w.Write([]string{"recipient", "error"})
+	for _, failure := range failures {
+		w.Write([]string{failure.recipient, failure.err.Error()})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		bootstrap.Fatalf("Failed to write '%s': %s", path, err)
+	}
+	if err := f.Close(); err != nil {
+		bootstrap.Fatalf("Failed to write '%s': %s", path, err)
+	}
+	fmt.Printf("The %d recipients that could not be deleted were written to '%s'\n", len(failures), path)
+}
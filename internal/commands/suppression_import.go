@@ -0,0 +1,568 @@
+package commands
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	sp "github.com/SparkPost/gosparkpost"
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/bootstrap"
+	"github.com/SparkPost/sparkpost-cli/internal/dedup"
+	"github.com/SparkPost/sparkpost-cli/internal/importer"
+	"github.com/SparkPost/sparkpost-cli/internal/validate"
+)
+
+// importBatchSize is the default number of entries uploaded per request.
+const importBatchSize = 1024 * 100
+
+func suppressionMandrill(c *cli.Context) {
+	importSuppressions(c, importer.Mandrill)
+}
+
+func suppressionSendgrid(c *cli.Context) {
+	importSuppressions(c, importer.SendGrid)
+}
+
+func suppressionMailgun(c *cli.Context) {
+	source, err := importer.MailgunKind(c.String("kind"))
+	if err != nil {
+		bootstrap.Fatalf("%s", err)
+		return
+	}
+	importSuppressions(c, source)
+}
+
+func suppressionSES(c *cli.Context) {
+	importSuppressions(c, importer.SES)
+}
+
+func suppressionPostmark(c *cli.Context) {
+	importSuppressions(c, importer.Postmark)
+}
+
+func suppressionMailjet(c *cli.Context) {
+	importSuppressions(c, importer.Mailjet)
+}
+
+func suppressionMailchimp(c *cli.Context) {
+	importSuppressions(c, importer.Mailchimp)
+}
+
+func suppressionImport(c *cli.Context) {
+	if c.String("map") == "" {
+		bootstrap.Fatalf("The `import` command requires a column mapping, for example --map email=0.")
+		return
+	}
+
+	importSuppressions(c, importer.CSVSource("import", "", importer.CSVOptions{
+		FieldsPerRecord: -1,
+		Description:     c.String("description"),
+	}))
+}
+
+// importReport counts what happened to the rows of an import.
+type importReport struct {
+	files    []string
+	rows     int64
+	accepted int64
+	skipped  map[string]int64
+	// types counts the entries to upload of each type.
+	types   map[string]int64
+	batches []int
+	// duplicates counts the entries left out because they were accepted
+	// before, in the same file or in a previous one.
+	withinFile  int64
+	acrossFiles int64
+	dedup       dedup.Stats
+}
+
+// print writes the report to w.
+func (r *importReport) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, file := range r.files {
+		fmt.Fprintf(tw, "File:\t%s\n", file)
+	}
+	fmt.Fprintf(tw, "Rows read:\t%d\n", r.rows)
+	fmt.Fprintf(tw, "Accepted:\t%d\n", r.accepted)
+	for _, typ := range []string{importer.Transactional, importer.NonTransactional} {
+		fmt.Fprintf(tw, "  %s\t%d\n", typ, r.types[typ])
+	}
+
+	var skipped int64
+	reasons := make([]string, 0, len(r.skipped))
+	for reason, count := range r.skipped {
+		skipped += count
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	fmt.Fprintf(tw, "Skipped:\t%d\n", skipped)
+	for _, reason := range reasons {
+		fmt.Fprintf(tw, "  %s\t%d\n", reason, r.skipped[reason])
+	}
+
+	fmt.Fprintf(tw, "Duplicate entries:\t%d\n", r.withinFile+r.acrossFiles)
+	fmt.Fprintf(tw, "  within a file\t%d\n", r.withinFile)
+	fmt.Fprintf(tw, "  across files\t%d\n", r.acrossFiles)
+	if r.dedup.Spilled > 0 {
+		fmt.Fprintf(tw, "  kept on disk\t%d of %d\n", r.dedup.Spilled, r.dedup.Keys)
+		fmt.Fprintf(tw, "  disk lookups\t%d (%d false positives)\n", r.dedup.Lookups, r.dedup.FalsePositives)
+	}
+
+	fmt.Fprintf(tw, "Batches:\t%d\n", len(r.batches))
+	for i, count := range r.batches {
+		fmt.Fprintf(tw, "  batch %d\t%d\n", i+1, count)
+	}
+	tw.Flush()
+}
+
+// suppressionImporter holds the state shared by the files of an import.
+type suppressionImporter struct {
+	c         *cli.Context
+	source    importer.Source
+	mapping   importer.Mapping
+	client    *sp.Client
+	dryRun    bool
+	batchSize int
+	// typ, when set, is the type of every entry. Otherwise rules give the
+	// type of the entries whose row does not.
+	typ   string
+	rules importer.TypeRules
+	// validation selects the optional checks of the imported addresses.
+	validation validate.Options
+	report     *importReport
+	rejects    *rejectsWriter
+	// seen holds the recipient and type of every entry accepted so far, with
+	// the index of its file.
+	seen *dedup.Set
+	// interrupted is closed on Ctrl-C.
+	interrupted chan struct{}
+}
+
+// importSuppressions uploads the entries of every `--file` in batches of
+// `--batch-size`, with up to `--concurrency` uploads in flight. Files are
+// imported one after the other, each with its own checkpoint recorded
+// whenever every batch up to a point has been uploaded, so `--resume` can
+// continue an import that failed or was interrupted part way through. With
+// `--dry-run` nothing is uploaded and a report of what would have been
+// imported is printed instead.
+func importSuppressions(c *cli.Context, source importer.Source) {
+	if len(c.StringSlice("file")) == 0 {
+		bootstrap.Fatalf("The `%s` command requires a file to import.", source.Name)
+		return
+	}
+	inputs, err := importer.ExpandInputs(c.StringSlice("file"))
+	if err != nil {
+		bootstrap.Fatalf("%s", err)
+		return
+	}
+	if len(inputs) > 1 && c.String("checkpoint") != "" {
+		bootstrap.Fatalf("--checkpoint can only be used when importing a single file.")
+		return
+	}
+
+	s := &suppressionImporter{
+		c:           c,
+		source:      source,
+		dryRun:      c.Bool("dry-run"),
+		batchSize:   c.Int("batch-size"),
+		report:      &importReport{skipped: map[string]int64{}, types: map[string]int64{}},
+		seen:        dedup.New(c.Int("dedup-memory")),
+		interrupted: make(chan struct{}),
+		validation: validate.Options{
+			Disposable: c.Bool("reject-disposable"),
+			Role:       c.Bool("reject-role"),
+		},
+	}
+	if s.batchSize < 1 {
+		bootstrap.Fatalf("--batch-size must be at least 1.")
+		return
+	}
+
+	spec := c.String("map")
+	if spec == "" {
+		spec = source.Mapping
+	}
+	if spec != "" {
+		if s.mapping, err = importer.ParseMapping(spec); err != nil {
+			bootstrap.Fatalf("%s", err)
+			return
+		}
+	}
+
+	if typ := c.String("type"); typ != "" {
+		if s.typ, err = importer.ParseType(typ); err != nil {
+			bootstrap.Fatalf("%s", err)
+			return
+		}
+	}
+	if s.rules, err = importer.ParseTypeRules(c.String("type-rules")); err != nil {
+		bootstrap.Fatalf("%s", err)
+		return
+	}
+
+	if !s.dryRun {
+		s.client = bootstrap.Client(c)
+
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(signals)
+		go func() {
+			<-signals
+			// A second Ctrl-C kills the process, even while a batch waits to be retried.
+			signal.Stop(signals)
+			fmt.Println("Interrupted, waiting for the batches being uploaded to finish... Press Ctrl-C again to abort.")
+			close(s.interrupted)
+		}()
+	}
+
+	s.rejects = newRejectsWriter(c.String("rejects"), len(inputs) > 1)
+	for _, in := range inputs {
+		fmt.Printf("Processing: %s\n", in)
+		s.importFile(in)
+	}
+	s.rejects.close()
+	s.closeSeen()
+
+	if s.dryRun {
+		fmt.Println("Dry run, nothing was uploaded.")
+		s.report.print(os.Stdout)
+		return
+	}
+	if duplicates := s.report.withinFile + s.report.acrossFiles; duplicates > 0 {
+		fmt.Printf("Left out %d duplicate entries, %d within a file and %d across files\n", duplicates, s.report.withinFile, s.report.acrossFiles)
+	}
+	fmt.Println("DONE")
+}
+
+// importFile imports the entries of in, exiting when it fails or is
+// interrupted. The standard input is imported without checkpoint.
+func (s *suppressionImporter) importFile(in importer.Input) {
+	file := in.String()
+	index := len(s.report.files)
+	s.report.files = append(s.report.files, file)
+
+	checkpoint := &importer.Checkpoint{File: file}
+	checkpointPath := s.c.String("checkpoint")
+	// Checkpoints are only required when asked for, otherwise an import from
+	// a read-only directory goes on without them.
+	explicit := checkpointPath != "" || s.c.Bool("resume")
+	if !s.dryRun {
+		if in.Path == importer.Stdin && (checkpointPath != "" || s.c.Bool("resume")) {
+			s.closeSeen()
+			bootstrap.Fatalf("The standard input cannot be read again, --checkpoint and --resume do not apply to it.")
+			return
+		}
+		if checkpointPath == "" {
+			checkpointPath = in.CheckpointPath()
+		}
+		if checkpointPath != "" {
+			checkpoint = resumeCheckpoint(s.c, in, checkpointPath)
+		}
+	}
+
+	f, err := in.Open()
+	if err != nil {
+		s.closeSeen()
+		bootstrap.Fatalf("%s", err)
+		return
+	}
+	defer f.Close()
+
+	rowReader, err := s.source.New(f, checkpoint.Position, s.mapping)
+	if err != nil {
+		s.closeSeen()
+		bootstrap.Fatalf("Failed to process '%s': %s", file, err)
+		return
+	}
+
+	var entries = []sp.WritableSuppressionEntry{}
+	var last *importer.Row
+	batchCount := checkpoint.Batch + 1
+	submitted := 0
+
+	var uploader *importer.Uploader
+	if !s.dryRun {
+		uploader = importer.NewUploader(s.c.Int("concurrency"), batchCount,
+			func(b *importer.Batch) error {
+				return bootstrap.Check(s.client.SuppressionUpsert(b.Entries))
+			},
+			func(b *importer.Batch) {
+				checkpoint.Rows = b.Rows
+				checkpoint.Offset = b.Offset
+				checkpoint.Batch = b.Number
+				if checkpointPath != "" {
+					if err := checkpoint.Save(checkpointPath); err != nil {
+						if explicit {
+							bootstrap.Fatal(err)
+						}
+						fmt.Printf("WARN: Continuing without checkpoint, the import cannot be resumed: %s\n", err)
+						checkpointPath = ""
+					}
+				}
+				fmt.Printf("Batch %d done\n", b.Number)
+			})
+
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-s.interrupted:
+				uploader.Stop()
+			case <-done:
+			}
+		}()
+	}
+
+	// submit hands the pending entries over as the next batch, returning false
+	// when the import must stop.
+	submit := func() bool {
+		s.report.batches = append(s.report.batches, len(entries))
+		batch := &importer.Batch{
+			Number:  batchCount,
+			Entries: entries,
+			Rows:    last.Number,
+			Offset:  last.Offset,
+		}
+		entries = []sp.WritableSuppressionEntry{}
+		batchCount++
+
+		if s.dryRun {
+			return true
+		}
+		if uploader.Stopped() {
+			return false
+		}
+		fmt.Printf("Uploading batch %d\n", batch.Number)
+		submitted++
+		return uploader.Submit(batch)
+	}
+
+	for {
+		if uploader != nil && uploader.Stopped() {
+			break
+		}
+
+		row, err := rowReader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var accepted []sp.WritableSuppressionEntry
+		if err == nil && row.Skip == "" {
+			if s.typ != "" {
+				row.Entry.Type = s.typ
+			}
+			s.rules.Apply(row)
+
+			if checkErr := s.validation.Check(row.Entry.Recipient); checkErr != nil {
+				row.Skip = checkErr.(*validate.Error).Reason
+			} else if accepted, err = s.unseen(row.Entry, index); err == nil && len(accepted) == 0 {
+				row.Skip = importer.SkipDuplicate
+			}
+		}
+
+		if err != nil {
+			if uploader != nil {
+				uploader.Stop()
+				uploader.Wait()
+			}
+			s.rejects.close()
+			s.closeSeen()
+			bootstrap.Fatalf("Failed to process '%s':\n\t%s", file, err)
+
+			return
+		}
+		s.report.rows++
+
+		if row.Skip != "" {
+			warnRejected(row)
+			s.report.skipped[row.Skip]++
+			s.rejects.write(file, row)
+			continue
+		}
+		s.report.accepted++
+		for _, e := range accepted {
+			s.report.types[e.Type]++
+		}
+		entries = append(entries, accepted...)
+		last = row
+
+		if len(entries) >= s.batchSize && !submit() {
+			break
+		}
+	}
+
+	if len(entries) > 0 && (uploader == nil || !uploader.Stopped()) {
+		submit()
+	}
+
+	if s.dryRun {
+		return
+	}
+
+	stopped := uploader.Stopped()
+	errs := uploader.Wait()
+
+	if stopped || len(errs) > 0 {
+		s.rejects.close()
+		s.closeSeen()
+		for _, err := range errs {
+			fmt.Printf("ERROR: %s\n", err)
+		}
+		if checkpoint.Batch > 0 && checkpointPath != "" {
+			fmt.Printf("Batches 1 to %d of '%s' were imported. Run the command again with --resume to continue from checkpoint '%s'.\n", checkpoint.Batch, file, checkpointPath)
+		}
+		if len(errs) > 0 {
+			bootstrap.Fatal(fmt.Errorf("%d of %d batches failed", len(errs), submitted))
+		}
+		bootstrap.Fatalf("Import interrupted.")
+		return
+	}
+
+	if checkpointPath == "" {
+		return
+	}
+	if err := os.Remove(checkpointPath); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("WARN: Failed to remove checkpoint '%s': %s\n", checkpointPath, err)
+	}
+}
+
+// warnRejected tells why row is ignored when its address was rejected.
+func warnRejected(row *importer.Row) {
+	if row.Skip != importer.SkipEmpty && validate.IsReason(row.Skip) {
+		fmt.Printf("WARN: Ignoring '%s'. %s.\n", row.Entry.Recipient, validate.Describe(row.Skip))
+	}
+}
+
+// unseen returns the entries to upload for entry of the file at index,
+// leaving out and counting those accepted before. Recipients are compared
+// case-insensitively.
+func (s *suppressionImporter) unseen(entry sp.WritableSuppressionEntry, index int) ([]sp.WritableSuppressionEntry, error) {
+	var entries []sp.WritableSuppressionEntry
+	for _, e := range importer.Expand(entry) {
+		first, seen, err := s.seen.Add(strings.ToLower(e.Recipient)+" "+e.Type, index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record '%s' for de-duplication: %s", e.Recipient, err)
+		}
+		switch {
+		case !seen:
+			entries = append(entries, e)
+		case first == index:
+			s.report.withinFile++
+		default:
+			s.report.acrossFiles++
+		}
+	}
+	return entries, nil
+}
+
+// closeSeen removes the recipients kept on disk for de-duplication, keeping
+// the statistics for the report.
+func (s *suppressionImporter) closeSeen() {
+	s.report.dedup = s.seen.Stats()
+	if err := s.seen.Close(); err != nil {
+		fmt.Printf("WARN: Failed to remove the de-duplication files: %s\n", err)
+	}
+}
+
+// rejectsWriter writes the rows that are not imported to the `--rejects` CSV,
+// each preceded by its row number and the reason it was skipped, and by the
+// file it comes from when several files are imported.
+type rejectsWriter struct {
+	path     string
+	withFile bool
+	f        *os.File
+	w        *csv.Writer
+}
+
+// newRejectsWriter creates the rejects file at path. Without a path rows are discarded.
+func newRejectsWriter(path string, withFile bool) *rejectsWriter {
+	r := &rejectsWriter{path: path, withFile: withFile}
+	if path == "" {
+		return r
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		bootstrap.Fatalf("Failed to create '%s': %s", path, err)
+	}
+	r.f = f
+	r.w = csv.NewWriter(f)
+	return r
+}
+
+func (r *rejectsWriter) write(file string, row *importer.Row) {
+	if r.w == nil {
+		return
+	}
+
+	prefix := []string{strconv.FormatInt(row.Number, 10), row.Skip}
+	if row.Skip == importer.SkipHeader {
+		file, prefix = "file", []string{"row", "skip_reason"}
+	}
+	if r.withFile {
+		prefix = append([]string{file}, prefix...)
+	}
+
+	r.w.Write(append(prefix, row.Record...))
+	if err := r.w.Error(); err != nil {
+		bootstrap.Fatalf("Failed to write '%s': %s", r.path, err)
+	}
+}
+
+func (r *rejectsWriter) close() {
+	if r.w == nil {
+		return
+	}
+
+	r.w.Flush()
+	if err := r.w.Error(); err != nil {
+		bootstrap.Fatalf("Failed to write '%s': %s", r.path, err)
+	}
+	if err := r.f.Close(); err != nil {
+		bootstrap.Fatalf("Failed to write '%s': %s", r.path, err)
+	}
+	r.w = nil
+}
+
+// resumeCheckpoint returns the checkpoint to start the import of in from:
+// the one saved at path when `--resume` is given and it matches the file of
+// in, otherwise a new one starting at the first row.
+func resumeCheckpoint(c *cli.Context, in importer.Input, path string) *importer.Checkpoint {
+	file := in.String()
+	sum, err := importer.HashFile(in.Path)
+	if err != nil {
+		bootstrap.Fatalf("Failed to read '%s': %s", in.Path, err)
+	}
+	fresh := &importer.Checkpoint{File: file, SHA256: sum}
+
+	saved, err := importer.LoadCheckpoint(path)
+	if err != nil {
+		bootstrap.Fatal(err)
+	}
+
+	switch {
+	case saved == nil:
+		if c.Bool("resume") {
+			fmt.Printf("WARN: No checkpoint found at '%s', starting from the first row.\n", path)
+		}
+		return fresh
+	case !c.Bool("resume"):
+		fmt.Printf("WARN: Ignoring checkpoint '%s' of a previous import, use --resume to continue it.\n", path)
+		return fresh
+	case saved.SHA256 != sum:
+		bootstrap.Fatalf("'%s' changed since checkpoint '%s' was written. Remove the checkpoint to import the file from the start.", file, path)
+	}
+
+	fmt.Printf("Resuming after batch %d, %d rows already processed\n", saved.Batch, saved.Rows)
+	return saved
+}
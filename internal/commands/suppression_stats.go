@@ -0,0 +1,238 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	sp "github.com/SparkPost/gosparkpost"
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/bootstrap"
+	"github.com/SparkPost/sparkpost-cli/internal/importer"
+	"github.com/SparkPost/sparkpost-cli/internal/output"
+)
+
+// Groups of the `stats` report.
+const (
+	statsTotal       = "total"
+	statsType        = "type"
+	statsSource      = "source"
+	statsDomain      = "domain"
+	statsDescription = "description"
+	statsCreated     = "created"
+	statsUpdated     = "updated"
+)
+
+// Keys of the entries that fall in no bucket of their group.
+const (
+	statsOther = "(other)"
+	statsNone  = "(none)"
+)
+
+const statsDay = "2006-01-02"
+
+// descriptionPrefix matches the short tags, such as `MBL:` or `SESB:`, that
+// the imports put at the start of descriptions.
+var descriptionPrefix = regexp.MustCompile(`^[A-Za-z0-9_-]{1,10}:`)
+
+var statsFlags = append([]cli.Flag{
+	cli.IntFlag{
+		Name:  "top",
+		Value: 10,
+		Usage: "Number of recipient domains listed, the others are counted together",
+	},
+	cli.IntFlag{
+		Name:  "days",
+		Value: 30,
+		Usage: "Number of days, up to today, of the created and updated histograms. Older entries are counted together",
+	},
+}, pickFlags(suppressionSearchFlags, suppressionFilterParameters...)...)
+
+// suppressionStats tallies the entries of the suppression list by group.
+type suppressionStats struct {
+	total       int64
+	types       map[string]int64
+	sources     map[string]int64
+	domains     map[string]int64
+	description map[string]int64
+	created     map[string]int64
+	updated     map[string]int64
+}
+
+func newSuppressionStats() *suppressionStats {
+	return &suppressionStats{
+		types:       map[string]int64{},
+		sources:     map[string]int64{},
+		domains:     map[string]int64{},
+		description: map[string]int64{},
+		created:     map[string]int64{},
+		updated:     map[string]int64{},
+	}
+}
+
+// suppressionStatistics pages through the entries matching the search flags
+// and writes their counts by type, source, recipient domain, description
+// prefix and creation and update day, one record per group and key.
+func suppressionStatistics(c *cli.Context) {
+	days := c.Int("days")
+	if days < 1 {
+		bootstrap.Fatalf("--days must be at least 1.")
+		return
+	}
+
+	client := bootstrap.Client(c)
+
+	parameters := collectParameters(c, suppressionFilterParameters)
+	parameters["per_page"] = exportPageSize
+
+	stats := newSuppressionStats()
+	err := eachSuppressionPage(c, client, parameters, func(suppressionPage *sp.SuppressionPage) error {
+		for _, entry := range suppressionPage.Results {
+			stats.add(entry)
+		}
+		fmt.Fprintf(os.Stderr, "Read %d entries\n", stats.total)
+		return nil
+	})
+	if err != nil {
+		bootstrap.Fatal(err)
+		return
+	}
+
+	out := newOutput(c, output.Table, "group", "key", "count")
+	stats.write(out, c.Int("top"), days, time.Now().UTC())
+	closeOutput(out)
+}
+
+// add counts entry.
+func (s *suppressionStats) add(entry *sp.SuppressionEntry) {
+	s.total++
+
+	switch {
+	case entry.Transactional && entry.NonTransactional:
+		s.types[importer.Both]++
+	case entry.Transactional:
+		s.types[importer.Transactional]++
+	case entry.NonTransactional:
+		s.types[importer.NonTransactional]++
+	case entry.Type != "":
+		s.types[entry.Type]++
+	default:
+		s.types[statsNone]++
+	}
+
+	source := entry.Source
+	if source == "" {
+		source = statsNone
+	}
+	s.sources[source]++
+
+	recipient := entry.Recipient
+	if recipient == "" {
+		recipient = entry.Email
+	}
+	domain := statsNone
+	if at := strings.LastIndex(recipient, "@"); at >= 0 {
+		domain = strings.ToLower(recipient[at+1:])
+	}
+	s.domains[domain]++
+
+	switch prefix := descriptionPrefix.FindString(entry.Description); {
+	case prefix != "":
+		s.description[prefix]++
+	case entry.Description == "":
+		s.description[statsNone]++
+	default:
+		s.description[statsOther]++
+	}
+
+	s.created[day(entry.Created)]++
+	s.updated[day(entry.Updated)]++
+}
+
+// day returns the date of the timestamp, such as 2016-04-11 for
+// 2016-04-11T20:15:55+00:00, or statsNone when it has none.
+func day(timestamp string) string {
+	if len(timestamp) < len(statsDay) {
+		return statsNone
+	}
+	return timestamp[:len(statsDay)]
+}
+
+// write writes the counts to out: every group sorted by decreasing count,
+// except the histograms which list the days up to now in order.
+func (s *suppressionStats) write(out output.Writer, top, days int, now time.Time) {
+	records := [][]interface{}{{statsTotal, "entries", s.total}}
+	records = append(records, byCount(statsType, s.types, 0)...)
+	records = append(records, byCount(statsSource, s.sources, 0)...)
+	records = append(records, byCount(statsDomain, s.domains, top)...)
+	records = append(records, byCount(statsDescription, s.description, 0)...)
+	records = append(records, byDay(statsCreated, s.created, days, now)...)
+	records = append(records, byDay(statsUpdated, s.updated, days, now)...)
+
+	for _, record := range records {
+		if err := out.Write(record...); err != nil {
+			bootstrap.Fatal(err)
+		}
+	}
+}
+
+// byCount returns the records of group by decreasing count, limited to the
+// top ones when top is positive.
+func byCount(group string, counts map[string]int64, top int) [][]interface{} {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	var records [][]interface{}
+	var other int64
+	for i, key := range keys {
+		if top > 0 && i >= top {
+			other += counts[key]
+			continue
+		}
+		records = append(records, []interface{}{group, key, counts[key]})
+	}
+	if other > 0 {
+		records = append(records, []interface{}{group, statsOther, other})
+	}
+	return records
+}
+
+// byDay returns the records of group for each of the last days up to now,
+// preceded by the count of the earlier days and followed by that of the
+// entries without date or dated after now.
+func byDay(group string, counts map[string]int64, days int, now time.Time) [][]interface{} {
+	first := now.AddDate(0, 0, 1-days).Format(statsDay)
+	last := now.Format(statsDay)
+
+	var earlier, other int64
+	for key, count := range counts {
+		switch {
+		case key == statsNone || key > last:
+			other += count
+		case key < first:
+			earlier += count
+		}
+	}
+
+	records := [][]interface{}{{group, "before " + first, earlier}}
+	for i := days - 1; i >= 0; i-- {
+		key := now.AddDate(0, 0, -i).Format(statsDay)
+		records = append(records, []interface{}{group, key, counts[key]})
+	}
+	if other > 0 {
+		records = append(records, []interface{}{group, statsOther, other})
+	}
+	return records
+}
@@ -0,0 +1,574 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	sp "github.com/SparkPost/gosparkpost"
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/backup"
+	"github.com/SparkPost/sparkpost-cli/internal/bootstrap"
+	"github.com/SparkPost/sparkpost-cli/internal/importer"
+	"github.com/SparkPost/sparkpost-cli/internal/output"
+)
+
+// Kinds of the differences between a local file and the suppression list.
+const (
+	changeAdd    = "add"
+	changeUpdate = "update"
+	changeRemove = "remove"
+	// changeKeep is not reported: it marks an entry to upload again after its
+	// recipient is deleted to remove its other type.
+	changeKeep = "keep"
+)
+
+// syncFilterParameters are the search parameters that scope the suppression
+// list compared with the file. `--description` names the description of the
+// entries of a CSV file instead.
+var syncFilterParameters = []string{"to", "from", "domain", "sources", "types"}
+
+var diffFlags = append([]cli.Flag{
+	cli.StringFlag{
+		Name:  "file, f",
+		Value: "",
+		Usage: "File holding the entries the suppression list must match",
+	},
+	cli.StringFlag{
+		Name:  "format",
+		Value: "csv",
+		Usage: "Format of the file: csv, read with --map, or ndjson, a backup written by export",
+	},
+	cli.StringFlag{
+		Name:  "map",
+		Value: "email=0",
+		Usage: "Column mapping of a CSV file. Columns are given by index (from 0) or header name. Example: email=0,type=reason,description=detail",
+	},
+	descriptionFlag,
+}, pickFlags(suppressionSearchFlags, syncFilterParameters...)...)
+
+var syncFlags = append(append(diffFlags, pickFlags(importFlags, "batch-size", "concurrency", "dry-run")...),
+	cli.BoolFlag{
+		Name:  "no-delete",
+		Usage: "Only add and update entries, never delete the entries missing from the file",
+	},
+	cli.BoolFlag{
+		Name:  "yes, y",
+		Usage: "Delete the entries missing from the file without asking for confirmation",
+	},
+	cli.StringFlag{
+		Name:  "recovery",
+		Value: "suppression-sync-recovery.ndjson",
+		Usage: "Backup written when the upload fails after recipients were deleted, holding the entries they keep. Restore it with `suppression restore`",
+	},
+)
+
+// suppressionChange is a difference between the file and the suppression list.
+type suppressionChange struct {
+	Change      string `json:"change"`
+	Recipient   string `json:"recipient"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	// Remote is the description in the suppression list.
+	Remote string `json:"remote,omitempty"`
+}
+
+var diffColumns = []string{"change", "recipient", "type", "description", "remote_description"}
+
+// diffSummary counts the differences found by diffSuppressions.
+type diffSummary struct {
+	local, remote, skipped     int64
+	add, update, remove, equal int64
+}
+
+func (s *diffSummary) print(w io.Writer) {
+	fmt.Fprintf(w, "Local entries: %d (%d rows skipped), remote entries: %d\n", s.local, s.skipped, s.remote)
+	fmt.Fprintf(w, "To add: %d, to update: %d, to remove: %d, unchanged: %d\n", s.add, s.update, s.remove, s.equal)
+}
+
+// localEntry is an entry of the file, held until the matching remote entry is found.
+type localEntry struct {
+	recipient   string
+	description string
+}
+
+func suppressionDiff(c *cli.Context) {
+	client := bootstrap.Client(c)
+
+	out := newOutput(c, output.CSV, diffColumns...)
+	summary, err := diffSuppressions(c, client, func(change *suppressionChange) error {
+		if change.Change == changeKeep {
+			return nil
+		}
+		return out.Write(change.Change, change.Recipient, change.Type, change.Description, change.Remote)
+	})
+	if err != nil {
+		bootstrap.Fatal(err)
+	}
+	closeOutput(out)
+	summary.print(os.Stderr)
+}
+
+// suppressionSync makes the suppression list match the file: it deletes the
+// recipients missing from the file, once confirmed, then uploads the entries
+// that are missing or differ. The changes are written to a temporary file
+// while the list is read, so they are only applied once every page has been
+// compared.
+func suppressionSync(c *cli.Context) {
+	if c.Bool("dry-run") {
+		suppressionDiff(c)
+		fmt.Fprintln(os.Stderr, "Dry run, nothing was changed.")
+		return
+	}
+
+	batchSize := c.Int("batch-size")
+	if batchSize < 1 {
+		bootstrap.Fatalf("--batch-size must be at least 1.")
+		return
+	}
+
+	client := bootstrap.Client(c)
+
+	changes, err := ioutil.TempFile("", "sparkpost-sync")
+	if err != nil {
+		bootstrap.Fatal(err)
+		return
+	}
+	defer os.Remove(changes.Name())
+	defer changes.Close()
+
+	w := bufio.NewWriter(changes)
+	enc := json.NewEncoder(w)
+	summary, err := diffSuppressions(c, client, func(change *suppressionChange) error {
+		return enc.Encode(change)
+	})
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		bootstrap.Fatal(err)
+		return
+	}
+	summary.print(os.Stdout)
+
+	noDelete := c.Bool("no-delete")
+	if !noDelete && summary.remove > 0 && !c.Bool("yes") && !confirmRemovals(c, changes.Name(), summary.remove) {
+		fmt.Println("No entries will be removed.")
+		noDelete = true
+	}
+	// Deleting and uploading again is not atomic: the entries kept by the
+	// deleted recipients are uploaded even when some deletions failed, and
+	// written to the recovery backup when they could not all be uploaded.
+	removed := !noDelete && summary.remove > 0
+	var removeErr error
+	if removed {
+		removeErr = applyRemovals(c, client, changes.Name())
+	}
+
+	if err := applyUpserts(c, client, changes.Name(), batchSize, removed); err != nil {
+		if removed {
+			writeRecovery(c.String("recovery"), changes.Name())
+		}
+		bootstrap.Fatal(err)
+		return
+	}
+	if removeErr != nil {
+		bootstrap.Fatal(removeErr)
+		return
+	}
+	fmt.Println("DONE")
+}
+
+// diffSuppressions compares the `--file` with the suppression list and calls
+// fn with every difference. The entries of the file are held in memory, the
+// suppression list, or the part of it matching the search flags, is read one
+// page at a time.
+//
+// Entries are compared by recipient, case-insensitively, and type; an entry
+// whose type changed is removed for the old type and added for the new one.
+// Descriptions are only compared when the file provides them.
+func diffSuppressions(c *cli.Context, client *sp.Client, fn func(*suppressionChange) error) (*diffSummary, error) {
+	file := c.String("file")
+	if file == "" {
+		return nil, fmt.Errorf("a file holding the entries to compare is required")
+	}
+
+	source, compareDescription, err := diffSource(c)
+	if err != nil {
+		return nil, err
+	}
+	summary := &diffSummary{}
+
+	local, err := readLocalEntries(c, file, source, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := collectParameters(c, syncFilterParameters)
+	parameters["per_page"] = exportPageSize
+	err = eachSuppressionPage(c, client, parameters, func(suppressionPage *sp.SuppressionPage) error {
+		for _, remote := range suppressionPage.Results {
+			if err := diffRemote(remote, local, compareDescription, summary, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for key, entry := range local {
+		summary.add++
+		change := &suppressionChange{
+			Change:      changeAdd,
+			Recipient:   entry.recipient,
+			Type:        key[strings.LastIndex(key, " ")+1:],
+			Description: entry.description,
+		}
+		if err := fn(change); err != nil {
+			return nil, err
+		}
+	}
+	return summary, nil
+}
+
+// diffSource returns the source reading the file, and whether it provides descriptions.
+func diffSource(c *cli.Context) (importer.Source, bool, error) {
+	switch c.String("format") {
+	case "ndjson":
+		return importer.Backup, true, nil
+	case "csv":
+		mapping, err := importer.ParseMapping(c.String("map"))
+		if err != nil {
+			return importer.Source{}, false, err
+		}
+		_, described := mapping[importer.FieldDescription]
+		return importer.CSVSource("sync", c.String("map"), importer.CSVOptions{
+			FieldsPerRecord: -1,
+			Description:     c.String("description"),
+		}), described, nil
+	}
+	return importer.Source{}, false, fmt.Errorf("unknown format '%s', expected csv or ndjson", c.String("format"))
+}
+
+// readLocalEntries returns the entries of file keyed by suppressionKey.
+// Descriptions are interned, as most entries of a file share the same one.
+func readLocalEntries(c *cli.Context, file string, source importer.Source, summary *diffSummary) (map[string]*localEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mapping importer.Mapping
+	if source.Mapping != "" {
+		if mapping, err = importer.ParseMapping(source.Mapping); err != nil {
+			return nil, err
+		}
+	}
+	rows, err := source.New(f, importer.Position{}, mapping)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process '%s': %s", file, err)
+	}
+
+	local := map[string]*localEntry{}
+	descriptions := map[string]string{}
+	for {
+		row, err := rows.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to process '%s': %s", file, err)
+		}
+
+		if row.Skip != "" {
+			if row.Skip != importer.SkipHeader {
+				summary.skipped++
+			}
+			continue
+		}
+
+		importer.DefaultTypeRules.Apply(row)
+
+		description, ok := descriptions[row.Entry.Description]
+		if !ok {
+			description = row.Entry.Description
+			descriptions[description] = description
+		}
+		for _, e := range importer.Expand(row.Entry) {
+			key := suppressionKey(e.Recipient, e.Type)
+			if _, ok := local[key]; !ok {
+				summary.local++
+			}
+			local[key] = &localEntry{recipient: e.Recipient, description: description}
+		}
+	}
+
+	if bootstrap.Verbose(c) {
+		fmt.Fprintf(os.Stderr, "Read %d entries from '%s'\n", summary.local, file)
+	}
+	return local, nil
+}
+
+// diffRemote compares an entry of the suppression list with the local entries
+// of its recipient, removing them from local.
+func diffRemote(remote *sp.SuppressionEntry, local map[string]*localEntry, compareDescription bool, summary *diffSummary, fn func(*suppressionChange) error) error {
+	recipient := remote.Recipient
+	if recipient == "" {
+		recipient = remote.Email
+	}
+
+	var types []string
+	if remote.Transactional || remote.Type == importer.Transactional {
+		types = append(types, importer.Transactional)
+	}
+	if remote.NonTransactional || remote.Type == importer.NonTransactional {
+		types = append(types, importer.NonTransactional)
+	}
+
+	var changes, kept []*suppressionChange
+	removed := false
+	for _, typ := range types {
+		summary.remote++
+		key := suppressionKey(recipient, typ)
+		entry, ok := local[key]
+		if !ok {
+			summary.remove++
+			removed = true
+			changes = append(changes, &suppressionChange{Change: changeRemove, Recipient: recipient, Type: typ, Remote: remote.Description})
+			continue
+		}
+		delete(local, key)
+
+		change := &suppressionChange{Recipient: entry.recipient, Type: typ, Description: entry.description, Remote: remote.Description}
+		if compareDescription && entry.description != remote.Description {
+			summary.update++
+			change.Change = changeUpdate
+			changes = append(changes, change)
+		} else {
+			// An entry uploaded again must not lose its description.
+			summary.equal++
+			change.Change = changeKeep
+			change.Description = remote.Description
+			kept = append(kept, change)
+		}
+	}
+
+	// Deleting a recipient removes every type, the others are uploaded again.
+	if removed {
+		changes = append(changes, kept...)
+	}
+	for _, change := range changes {
+		if err := fn(change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// confirmRemovals shows some of the recipients to delete according to the
+// changes recorded at path and asks for confirmation. The whole list is
+// compared unless the search flags scope it, SparkPost's own bounces and
+// spam complaints included, which calls for a warning.
+func confirmRemovals(c *cli.Context, path string, count int64) bool {
+	var sample []string
+	last := ""
+	err := eachChange(path, func(change *suppressionChange) error {
+		if change.Change == changeRemove && change.Recipient != last {
+			last = change.Recipient
+			sample = append(sample, change.Recipient)
+			if len(sample) == deleteSampleSize {
+				return io.EOF
+			}
+		}
+		return nil
+	})
+	if err != nil && err != io.EOF {
+		bootstrap.Fatal(err)
+	}
+
+	fmt.Printf("%d entries missing from the file will be removed from the suppression list, for example:\n", count)
+	for _, recipient := range sample {
+		fmt.Printf("  %s\n", recipient)
+	}
+	if len(collectParameters(c, syncFilterParameters)) == 0 {
+		fmt.Printf("WARN: The whole suppression list was compared, including the bounces and spam complaints recorded by SparkPost. Use --%s to compare part of it only, or --no-delete.\n", strings.Join(syncFilterParameters, ", --"))
+	}
+	return confirm("Remove them?")
+}
+
+// suppressionKey identifies the entry of recipient for a type.
+func suppressionKey(recipient, typ string) string {
+	return strings.ToLower(recipient) + " " + typ
+}
+
+// eachChange calls fn with every change recorded in the file at path.
+func eachChange(path string, fn func(*suppressionChange) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		change := &suppressionChange{}
+		if err := dec.Decode(change); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(change); err != nil {
+			return err
+		}
+	}
+}
+
+// applyRemovals deletes the recipients of the remove changes recorded at path.
+func applyRemovals(c *cli.Context, client *sp.Client, path string) error {
+	queue := make(chan string)
+	errs := make(chan error, 1)
+	go func() {
+		last := ""
+		errs <- eachChange(path, func(change *suppressionChange) error {
+			if change.Change == changeRemove && change.Recipient != last {
+				last = change.Recipient
+				queue <- change.Recipient
+			}
+			return nil
+		})
+		close(queue)
+	}()
+
+	failures := deleteRecipients(client, c.Int("concurrency"), 0, queue)
+	if err := <-errs; err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		reportDeleteFailures("", failures)
+		return fmt.Errorf("%d recipients could not be deleted", len(failures))
+	}
+	return nil
+}
+
+// applyUpserts uploads the entries of the add and update changes recorded at
+// path, and of the keep changes when recipients were deleted.
+func applyUpserts(c *cli.Context, client *sp.Client, path string, batchSize int, keep bool) error {
+	uploader := importer.NewUploader(c.Int("concurrency"), 1,
+		func(b *importer.Batch) error {
+			return bootstrap.Check(client.SuppressionUpsert(b.Entries))
+		},
+		func(b *importer.Batch) {
+			fmt.Printf("Batch %d done\n", b.Number)
+		})
+
+	var entries []sp.WritableSuppressionEntry
+	number := 1
+	// submit hands the pending entries over as the next batch, returning false
+	// when a batch failed.
+	submit := func() bool {
+		if uploader.Stopped() {
+			return false
+		}
+		fmt.Printf("Uploading batch %d\n", number)
+		batch := &importer.Batch{Number: number, Entries: entries}
+		entries = nil
+		number++
+		return uploader.Submit(batch)
+	}
+
+	err := eachChange(path, func(change *suppressionChange) error {
+		switch change.Change {
+		case changeAdd, changeUpdate:
+		case changeKeep:
+			if !keep {
+				return nil
+			}
+		default:
+			return nil
+		}
+
+		entries = append(entries, sp.WritableSuppressionEntry{
+			Recipient:   change.Recipient,
+			Type:        change.Type,
+			Description: change.Description,
+		})
+		if len(entries) >= batchSize && !submit() {
+			return io.EOF
+		}
+		return nil
+	})
+	if err == nil && len(entries) > 0 {
+		submit()
+	}
+
+	errs := uploader.Wait()
+	for _, e := range errs {
+		fmt.Printf("ERROR: %s\n", e)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d batches failed", len(errs), number-1)
+	}
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// writeRecovery writes the entries of the keep changes recorded at path to a
+// backup at file, so that the recipients deleted by a sync that failed can be
+// restored. They are listed when the backup cannot be written.
+func writeRecovery(file, path string) {
+	var kept []backup.Entry
+	err := eachChange(path, func(change *suppressionChange) error {
+		if change.Change == changeKeep {
+			kept = append(kept, backup.Entry{
+				Recipient:        change.Recipient,
+				Transactional:    change.Type == importer.Transactional,
+				NonTransactional: change.Type == importer.NonTransactional,
+				Description:      change.Description,
+			})
+		}
+		return nil
+	})
+	if err != nil || len(kept) == 0 {
+		return
+	}
+
+	w, err := backup.Create(file, backup.IsGzip(file), nil)
+	if err == nil {
+		for _, e := range kept {
+			if err = w.Write(e); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			_, err = w.Close()
+		}
+		if err != nil {
+			w.Remove()
+		}
+	}
+	if err != nil {
+		fmt.Printf("ERROR: Failed to write '%s': %s. These entries of deleted recipients may not have been uploaded again:\n", file, err)
+		for _, e := range kept {
+			fmt.Printf("  %s\t%s\t%s\n", e.Recipient, typeOf(e), e.Description)
+		}
+		return
+	}
+	fmt.Printf("Wrote the %d entries of deleted recipients to upload again to '%s'. Restore them with `sparkpost suppression restore --file %s`.\n", len(kept), file, file)
+}
+
+// typeOf returns the type of a backup entry holding a single type.
+func typeOf(e backup.Entry) string {
+	if e.Transactional {
+		return importer.Transactional
+	}
+	return importer.NonTransactional
+}
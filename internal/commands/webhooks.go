@@ -0,0 +1,143 @@
+package commands
+
+import (
+	sp "github.com/SparkPost/gosparkpost"
+	"github.com/codegangsta/cli"
+
+	"github.com/SparkPost/sparkpost-cli/internal/bootstrap"
+	"github.com/SparkPost/sparkpost-cli/internal/output"
+)
+
+var webhooksParameters = []string{
+	"timezone", "limit",
+}
+
+var webhookTimezoneFlag = cli.StringFlag{
+	Name:  "timezone, tz",
+	Value: "",
+	Usage: "Optional Standard timezone identification string, defaults to UTC Example: America/New_York.",
+}
+
+var webhookIDFlag = cli.StringFlag{
+	Name:  "id",
+	Value: "",
+	Usage: "UUID identifying a webhook Example: 12affc24-f183-11e3-9234-3c15c2c818c2.",
+}
+
+var webhookLimitFlag = cli.StringFlag{
+	Name:  "limit",
+	Value: "",
+	Usage: "Optional Maximum number of results to return. Defaults to 1000. Example: 1000.",
+}
+
+var webhooksCommand = cli.Command{
+	Name:  "webhooks",
+	Usage: "List, review and query webhooks\n\n\tSee https://developers.sparkpost.com/api/webhooks.html",
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "List currently extant webhooks",
+			Flags:  []cli.Flag{webhookTimezoneFlag, webhookLimitFlag},
+			Action: webhooksList,
+		},
+		{
+			Name:   "query",
+			Usage:  "Retrieve details about a webhook by specifying its id",
+			Flags:  []cli.Flag{webhookIDFlag, webhookTimezoneFlag},
+			Action: webhooksQuery,
+		},
+		{
+			Name:   "status",
+			Usage:  "Retrieve status information regarding batches that have been generated for the given webhook",
+			Flags:  []cli.Flag{webhookIDFlag, webhookTimezoneFlag, webhookLimitFlag},
+			Action: webhooksStatus,
+		},
+	},
+}
+
+func webhooksStatus(c *cli.Context) {
+	client := bootstrap.Client(c)
+
+	statusWrapper := &sp.WebhookStatusWrapper{}
+	statusWrapper.Params = collectParameters(c, webhooksParameters)
+	statusWrapper.ID = c.String("id")
+
+	if err := bootstrap.Check(client.WebhookStatus(statusWrapper)); err != nil {
+		bootstrap.Fatal(err)
+	}
+	if err := bootstrap.Errors(statusWrapper.Errors); err != nil {
+		bootstrap.Fatal(err)
+	}
+
+	out := newOutput(c, output.Table, webhookStatusColumns...)
+	for _, element := range statusWrapper.Results {
+		writeWebhookStatus(out, element)
+	}
+	closeOutput(out)
+}
+
+func webhooksQuery(c *cli.Context) {
+	client := bootstrap.Client(c)
+
+	queryWrapper := &sp.WebhookQueryWrapper{}
+	queryWrapper.Params = collectParameters(c, webhooksParameters)
+	queryWrapper.ID = c.String("id")
+
+	if err := bootstrap.Check(client.QueryWebhook(queryWrapper)); err != nil {
+		bootstrap.Fatal(err)
+	}
+	if err := bootstrap.Errors(queryWrapper.Errors); err != nil {
+		bootstrap.Fatal(err)
+	}
+
+	out := newOutput(c, output.Table, append(webhookColumns, "events")...)
+	writeWebhook(out, queryWrapper.Results, true)
+	closeOutput(out)
+}
+
+func webhooksList(c *cli.Context) {
+	client := bootstrap.Client(c)
+
+	listWrapper := &sp.WebhookListWrapper{}
+	listWrapper.Params = collectParameters(c, webhooksParameters)
+
+	if err := bootstrap.Check(client.Webhooks(listWrapper)); err != nil {
+		bootstrap.Fatal(err)
+	}
+	if err := bootstrap.Errors(listWrapper.Errors); err != nil {
+		bootstrap.Fatal(err)
+	}
+
+	out := newOutput(c, output.Table, webhookColumns...)
+	for _, element := range listWrapper.Results {
+		writeWebhook(out, element, false)
+	}
+	closeOutput(out)
+}
+
+// webhookColumns names the fields written for each webhook by `webhooks list`;
+// `webhooks query` adds the subscribed events.
+var webhookColumns = []string{
+	"id", "name", "target", "last_successful", "last_failure", "auth_type",
+}
+
+var webhookStatusColumns = []string{
+	"batch_id", "ts", "attempts", "response_code",
+}
+
+func writeWebhook(out output.Writer, event *sp.WebhookItem, withEvents bool) {
+	values := []interface{}{event.ID, event.Name, event.Target, event.LastSuccessful, event.LastFailure, event.AuthType}
+	if withEvents {
+		values = append(values, event.Events)
+	}
+
+	if err := out.Write(values...); err != nil {
+		bootstrap.Fatal(err)
+	}
+}
+
+func writeWebhookStatus(out output.Writer, event *sp.WebhookStatus) {
+	if err := out.Write(event.BatchID, event.Timestamp, event.Attempts, event.ResponseCode); err != nil {
+		bootstrap.Fatal(err)
+	}
+}
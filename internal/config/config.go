@@ -0,0 +1,124 @@
+// Package config reads and writes the `sparkpost` configuration file, which
+// holds named profiles for the accounts, subaccounts and regions in use.
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Profile holds the connection settings and defaults for one account.
+type Profile struct {
+	BaseURL    string `yaml:"base_url,omitempty"`
+	APIKey     string `yaml:"api_key,omitempty"`
+	Username   string `yaml:"username,omitempty"`
+	Password   string `yaml:"password,omitempty"`
+	Subaccount string `yaml:"subaccount,omitempty"`
+	Timezone   string `yaml:"timezone,omitempty"`
+	Output     string `yaml:"output,omitempty"`
+
+	// MaxRPS limits the API requests made per second, unlimited when zero.
+	MaxRPS float64 `yaml:"max_rps,omitempty"`
+}
+
+// Merge copies every non-empty (or positive) setting of other into p.
+func (p *Profile) Merge(other Profile) {
+	set := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	set(&p.BaseURL, other.BaseURL)
+	set(&p.APIKey, other.APIKey)
+	set(&p.Username, other.Username)
+	set(&p.Password, other.Password)
+	set(&p.Subaccount, other.Subaccount)
+	set(&p.Timezone, other.Timezone)
+	set(&p.Output, other.Output)
+	if other.MaxRPS > 0 {
+		p.MaxRPS = other.MaxRPS
+	}
+}
+
+// Config is the content of the configuration file.
+type Config struct {
+	// Current names the profile used when none is requested explicitly.
+	Current  string              `yaml:"current,omitempty"`
+	Profiles map[string]*Profile `yaml:"profiles,omitempty"`
+}
+
+// DefaultPath returns the location of the configuration file:
+// $XDG_CONFIG_HOME/sparkpost/config.yaml, or ~/.config/sparkpost/config.yaml.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home := os.Getenv("HOME")
+		if home == "" {
+			home = os.Getenv("USERPROFILE")
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "sparkpost", "config.yaml")
+}
+
+// Load reads the configuration file at path. A missing file yields an empty Config.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration file '%s': %s", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes cfg to path, readable by the current user only since profiles hold credentials.
+func (cfg *Config) Save(path string) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// Profile returns the profile called name, or the current profile when name is empty.
+// It returns nil without error when no profile is requested and none is current.
+func (cfg *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = cfg.Current
+		if name == "" {
+			return nil, nil
+		}
+	}
+
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile '%s' not found", name)
+	}
+	return p, nil
+}
+
+// Names returns the profile names in alphabetical order.
+func (cfg *Config) Names() []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
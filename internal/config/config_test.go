@@ -0,0 +1,99 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadMissing(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, &Config{}) {
+		t.Errorf("Load() = %+v, want an empty configuration", cfg)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, []byte("profiles: [prod\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load accepted an invalid file")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sparkpost", "config.yaml")
+	saved := &Config{
+		Current: "prod",
+		Profiles: map[string]*Profile{
+			"prod":   {BaseURL: "https://api.eu.sparkpost.com", APIKey: "key", Subaccount: "7", Timezone: "Europe/Paris", Output: "json", MaxRPS: 2.5},
+			"legacy": {Username: "user", Password: "secret"},
+		},
+	}
+	if err := saved.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("configuration file mode %o, want 600", perm)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, saved) {
+		t.Errorf("Load() = %+v, want %+v", loaded, saved)
+	}
+	if names := loaded.Names(); !reflect.DeepEqual(names, []string{"legacy", "prod"}) {
+		t.Errorf("Names() = %v", names)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	prod, legacy := &Profile{APIKey: "key"}, &Profile{Username: "user"}
+	cfg := &Config{Current: "prod", Profiles: map[string]*Profile{"prod": prod, "legacy": legacy}}
+
+	if p, err := cfg.Profile(""); p != prod || err != nil {
+		t.Errorf("Profile(\"\") = %v, %v, want the current profile", p, err)
+	}
+	if p, err := cfg.Profile("legacy"); p != legacy || err != nil {
+		t.Errorf("Profile(legacy) = %v, %v", p, err)
+	}
+	if _, err := cfg.Profile("staging"); err == nil {
+		t.Error("Profile(staging) found a profile that does not exist")
+	}
+
+	cfg.Current = ""
+	if p, err := cfg.Profile(""); p != nil || err != nil {
+		t.Errorf("Profile(\"\") = %v, %v without current profile, want none", p, err)
+	}
+}
+
+// TestMerge checks that the settings given override those of the profile,
+// and that the settings left empty keep them.
+func TestMerge(t *testing.T) {
+	p := Profile{BaseURL: "https://api.eu.sparkpost.com", APIKey: "old-key", Subaccount: "7", Output: "json", MaxRPS: 2}
+	p.Merge(Profile{APIKey: "new-key", Timezone: "America/New_York", MaxRPS: 0})
+	want := Profile{BaseURL: "https://api.eu.sparkpost.com", APIKey: "new-key", Subaccount: "7", Timezone: "America/New_York", Output: "json", MaxRPS: 2}
+	if p != want {
+		t.Errorf("Merge() = %+v, want %+v", p, want)
+	}
+
+	p.Merge(Profile{BaseURL: "https://api.sparkpost.com", Username: "user", Password: "secret", Subaccount: "8", Output: "csv", MaxRPS: 0.5})
+	want = Profile{BaseURL: "https://api.sparkpost.com", APIKey: "new-key", Username: "user", Password: "secret", Subaccount: "8", Timezone: "America/New_York", Output: "csv", MaxRPS: 0.5}
+	if p != want {
+		t.Errorf("Merge() = %+v, want %+v", p, want)
+	}
+}
@@ -0,0 +1,53 @@
+package dedup
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// bloom is a bloom filter. Its k bit positions are derived from the two
+// halves of a 64-bit FNV-1a hash.
+type bloom struct {
+	bits []uint64
+	m    uint64
+	k    uint64
+}
+
+// newBloom returns a bloom filter holding n keys with the false positive rate p.
+func newBloom(n int, p float64) *bloom {
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint64(math.Ceil(math.Ln2 * float64(m) / float64(n)))
+	if k < 1 {
+		k = 1
+	}
+	return &bloom{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+func (b *bloom) hashes(key string) (uint64, uint64) {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	sum := h.Sum64()
+	return sum & 0xffffffff, sum>>32 | 1
+}
+
+func (b *bloom) add(key string) {
+	h1, h2 := b.hashes(key)
+	for i := uint64(0); i < b.k; i++ {
+		bit := (h1 + i*h2) % b.m
+		b.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+func (b *bloom) has(key string) bool {
+	h1, h2 := b.hashes(key)
+	for i := uint64(0); i < b.k; i++ {
+		bit := (h1 + i*h2) % b.m
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
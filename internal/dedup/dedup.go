@@ -0,0 +1,162 @@
+// Package dedup tells whether the keys of a large stream were seen before
+// while keeping a bounded number of them in memory. Once the limit is
+// reached the keys are moved to a temporary bolt database, fronted by a bloom
+// filter so that most new keys are recognized without reading the disk.
+package dedup
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/boltdb/bolt"
+)
+
+// DefaultLimit is the default number of keys kept in memory.
+const DefaultLimit = 1000000
+
+var bucket = []byte("keys")
+
+// Stats counts the work of a Set.
+type Stats struct {
+	// Keys is the number of distinct keys added.
+	Keys int64
+	// Spilled is the number of keys moved to disk.
+	Spilled int64
+	// Lookups is the number of keys looked up on disk, and FalsePositives
+	// those that the bloom filter wrongly reported as present.
+	Lookups        int64
+	FalsePositives int64
+}
+
+// Set is a set of keys, each recorded with the number of the file it was
+// first seen in. It is not safe for concurrent use.
+type Set struct {
+	limit  int
+	memory map[string]int32
+	dir    string
+	db     *bolt.DB
+	bloom  *bloom
+	stats  Stats
+}
+
+// New returns an empty set keeping up to limit keys in memory, or
+// DefaultLimit when limit is not positive.
+func New(limit int) *Set {
+	if limit < 1 {
+		limit = DefaultLimit
+	}
+	return &Set{limit: limit, memory: map[string]int32{}}
+}
+
+// Add records key as seen in file. When key was seen before, Add returns the
+// file it was first seen in and true.
+func (s *Set) Add(key string, file int) (int, bool, error) {
+	if first, ok := s.memory[key]; ok {
+		return int(first), true, nil
+	}
+
+	if s.db != nil && s.bloom.has(key) {
+		s.stats.Lookups++
+		var value []byte
+		err := s.db.View(func(tx *bolt.Tx) error {
+			if v := tx.Bucket(bucket).Get([]byte(key)); v != nil {
+				value = append(value, v...)
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, false, err
+		}
+		if value != nil {
+			return int(binary.BigEndian.Uint32(value)), true, nil
+		}
+		s.stats.FalsePositives++
+	}
+
+	s.memory[key] = int32(file)
+	s.stats.Keys++
+	if len(s.memory) >= s.limit {
+		return file, false, s.spill()
+	}
+	return file, false, nil
+}
+
+// spill moves the keys held in memory to disk in a single transaction.
+func (s *Set) spill() error {
+	if s.db == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		for key, file := range s.memory {
+			// Bolt keeps the value slice until the commit, each key needs its own.
+			value := make([]byte, 4)
+			binary.BigEndian.PutUint32(value, uint32(file))
+			if err := b.Put([]byte(key), value); err != nil {
+				return err
+			}
+			s.bloom.add(key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.stats.Spilled += int64(len(s.memory))
+	s.memory = map[string]int32{}
+	return nil
+}
+
+// open creates the temporary database and its bloom filter, sized for 8
+// spills at a 1% false positive rate. More keys only raise the rate.
+func (s *Set) open() error {
+	dir, err := ioutil.TempDir("", "sparkpost-dedup-")
+	if err != nil {
+		return err
+	}
+
+	db, err := bolt.Open(filepath.Join(dir, "keys.db"), 0600, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		return err
+	}
+	// The database is thrown away when done, there is nothing to recover.
+	db.NoSync = true
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucket(bucket)
+		return err
+	}); err != nil {
+		db.Close()
+		os.RemoveAll(dir)
+		return err
+	}
+
+	s.dir, s.db = dir, db
+	s.bloom = newBloom(8*s.limit, 0.01)
+	return nil
+}
+
+// Stats returns the counts of the set so far.
+func (s *Set) Stats() Stats {
+	return s.stats
+}
+
+// Close removes the keys kept on disk, if any.
+func (s *Set) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	err := s.db.Close()
+	if rmErr := os.RemoveAll(s.dir); err == nil {
+		err = rmErr
+	}
+	s.db = nil
+	return err
+}
@@ -0,0 +1,86 @@
+package dedup
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestSetSpill spreads keys over several files with a limit small enough for
+// most of them to be kept on disk, then adds them again from another file.
+func TestSetSpill(t *testing.T) {
+	s := New(10)
+	defer s.Close()
+
+	const keys, files = 100, 4
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("recipient-%d@example.com", i)
+		if first, seen, err := s.Add(key, i%files); err != nil {
+			t.Fatal(err)
+		} else if seen || first != i%files {
+			t.Errorf("Add(%s, %d) = %d, %v, want %d, false", key, i%files, first, seen, i%files)
+		}
+	}
+	if stats := s.Stats(); stats.Keys != keys || stats.Spilled != keys {
+		t.Errorf("Stats() = %+v, want %d keys all spilled", stats, keys)
+	}
+
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("recipient-%d@example.com", i)
+		if first, seen, err := s.Add(key, files); err != nil {
+			t.Fatal(err)
+		} else if !seen || first != i%files {
+			t.Errorf("Add(%s) again = %d, %v, want %d, true", key, first, seen, i%files)
+		}
+	}
+	if stats := s.Stats(); stats.Lookups != keys || stats.FalsePositives != 0 {
+		t.Errorf("Stats() = %+v, want %d lookups and no false positives", stats, keys)
+	}
+}
+
+// TestSetBloomMiss adds new keys, fewer than the limit, once some are on disk:
+// those missing the bloom filter are not looked up, and none is reported as
+// seen.
+func TestSetBloomMiss(t *testing.T) {
+	const limit = 100
+	s := New(limit)
+	defer s.Close()
+
+	for i := 0; i < limit; i++ {
+		if _, _, err := s.Add(fmt.Sprintf("old-%d@example.com", i), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := s.Stats(); stats.Spilled != limit {
+		t.Fatalf("Stats() = %+v, want %d keys spilled", stats, limit)
+	}
+
+	for i := 0; i < limit-1; i++ {
+		key := fmt.Sprintf("new-%d@example.com", i)
+		if _, seen, err := s.Add(key, 1); err != nil {
+			t.Fatal(err)
+		} else if seen {
+			t.Errorf("Add(%s) reported a key never added as seen", key)
+		}
+	}
+
+	stats := s.Stats()
+	if stats.Lookups != stats.FalsePositives {
+		t.Errorf("Stats() = %+v, every disk lookup of a new key must be a false positive", stats)
+	}
+	if stats.Lookups > limit/10 {
+		t.Errorf("Stats() = %+v, the bloom filter let most new keys through", stats)
+	}
+}
+
+func TestSetCloseWithoutSpill(t *testing.T) {
+	s := New(10)
+	if _, seen, err := s.Add("name@example.com", 0); err != nil || seen {
+		t.Fatalf("Add() = %v, %v", seen, err)
+	}
+	if _, seen, _ := s.Add("name@example.com", 1); !seen {
+		t.Error("Add() did not report the key kept in memory as seen")
+	}
+	if err := s.Close(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,106 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	sp "github.com/SparkPost/gosparkpost"
+
+	"github.com/SparkPost/sparkpost-cli/internal/backup"
+)
+
+// Backup restores the backups written by `suppression export`, gzip
+// compressed or not, keeping the types and description of every entry.
+// Recipients are restored exactly as exported, only empty ones are skipped.
+var Backup = Source{
+	Name: "restore",
+	New: func(r io.ReadSeeker, start Position, m Mapping) (Importer, error) {
+		if m != nil {
+			return nil, fmt.Errorf("backups have a fixed format, --map is not supported")
+		}
+		return NewBackup(r, start)
+	},
+}
+
+// backupReader reads the lines of a backup. It is resumed by skipping
+// start.Rows lines, the offset of its rows is always 0.
+type backupReader struct {
+	scanner *bufio.Scanner
+	rows    int64
+}
+
+// NewBackup returns an importer reading the backup r from start.
+func NewBackup(r io.ReadSeeker, start Position) (Importer, error) {
+	in := bufio.NewReader(r)
+	if magic, _ := in.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, err
+		}
+		in = bufio.NewReader(gz)
+	}
+
+	b := &backupReader{scanner: bufio.NewScanner(in)}
+	b.scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for b.rows < start.Rows && b.scanner.Scan() {
+		b.rows++
+	}
+	return b, b.scanner.Err()
+}
+
+func (b *backupReader) Read() (*Row, error) {
+	if !b.scanner.Scan() {
+		if err := b.scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	b.rows++
+	line := b.scanner.Text()
+
+	row := &Row{Number: b.rows, Record: []string{line}}
+	if len(bytes.TrimSpace([]byte(line))) == 0 {
+		row.Skip = SkipEmpty
+		return row, nil
+	}
+
+	var e backup.Entry
+	if err := json.Unmarshal([]byte(line), &e); err != nil {
+		return nil, fmt.Errorf("line %d: %s", b.rows, err)
+	}
+	row.Entry = sp.WritableSuppressionEntry{
+		Recipient:   e.Recipient,
+		Type:        backupType(e),
+		Description: e.Description,
+	}
+
+	// The suppression list accepted the recipients, which may not pass
+	// validate.Normalize: they are uploaded again unchanged.
+	switch {
+	case strings.TrimSpace(e.Recipient) == "":
+		row.Skip = SkipEmpty
+	case row.Entry.Type == "":
+		row.Skip = SkipNotSuppressed
+	}
+	return row, nil
+}
+
+// backupType returns the type of the entry to restore for e.
+func backupType(e backup.Entry) string {
+	switch {
+	case e.Transactional && e.NonTransactional:
+		return Both
+	case e.Transactional:
+		return Transactional
+	case e.NonTransactional:
+		return NonTransactional
+	case e.Type == Transactional, e.Type == NonTransactional:
+		return e.Type
+	}
+	return ""
+}
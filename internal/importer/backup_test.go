@@ -0,0 +1,56 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SparkPost/sparkpost-cli/internal/backup"
+)
+
+// TestBackupRoundTrip restores a backup written as by `suppression export`:
+// recipients come back exactly as exported, even those validate.Normalize
+// would change or reject.
+func TestBackupRoundTrip(t *testing.T) {
+	for _, name := range []string{"suppressions.ndjson", "suppressions.ndjson.gz"} {
+		path := filepath.Join(t.TempDir(), name)
+		w, err := backup.Create(path, backup.IsGzip(path), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range []backup.Entry{
+			{Recipient: "Name@Bücher.DE", Transactional: true, NonTransactional: true, Description: "both"},
+			{Recipient: "name@localhost", NonTransactional: true, Description: "not a public domain"},
+			{Recipient: `"john doe"@example.com`, Type: Transactional, Description: "legacy type"},
+			{Recipient: " ", Transactional: true},
+			{Recipient: "untyped@example.com"},
+		} {
+			if err := w.Write(e); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows := readRows(t, Backup, f, Position{})
+		f.Close()
+
+		checkRows(t, rows, []wantRow{
+			{"Name@Bücher.DE", "", "both", ""},
+			{"name@localhost", "", "not a public domain", ""},
+			{`"john doe"@example.com`, "", "legacy type", ""},
+			{" ", "", "", SkipEmpty},
+			{"untyped@example.com", "", "", SkipNotSuppressed},
+		})
+		for i, typ := range []string{Both, NonTransactional, Transactional} {
+			if i < len(rows) && rows[i].Entry.Type != typ {
+				t.Errorf("%s: row %d has type %q, want %q", name, i+1, rows[i].Entry.Type, typ)
+			}
+		}
+	}
+}
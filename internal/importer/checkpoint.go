@@ -0,0 +1,94 @@
+// Package importer holds the building blocks of the suppression list import
+// commands, such as the checkpoints that let an interrupted import resume.
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CheckpointSuffix is appended to the input file name to locate its default checkpoint.
+const CheckpointSuffix = ".checkpoint"
+
+// Checkpoint records how far the import of a file got: every row before
+// Position has been read and every entry taken from them has been uploaded.
+type Checkpoint struct {
+	// File and SHA256 identify the input, so a checkpoint is never applied to another file.
+	File   string `json:"file"`
+	SHA256 string `json:"sha256"`
+	// Position is that of the first record not read yet: Rows counts every
+	// record before it, including headers and skipped rows.
+	Position
+	// Batch is the number of the last batch uploaded successfully.
+	Batch   int       `json:"batch"`
+	Updated time.Time `json:"updated"`
+}
+
+// CheckpointPath returns the default checkpoint location for file.
+func CheckpointPath(file string) string {
+	return file + CheckpointSuffix
+}
+
+// LoadCheckpoint reads the checkpoint at path. It returns nil without error when there is none.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint '%s': %s", path, err)
+	}
+	return cp, nil
+}
+
+// Save writes cp to path. The file is replaced atomically so an interrupted
+// import never leaves a truncated checkpoint behind.
+func (cp *Checkpoint) Save(path string) error {
+	cp.Updated = time.Now().UTC()
+
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// HashFile returns the hex encoded SHA-256 digest of the file at path.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,228 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	sp "github.com/SparkPost/gosparkpost"
+)
+
+// Suppression entry types. Both is not a SparkPost type: entries of type
+// Both are uploaded as one transactional and one non_transactional entry.
+const (
+	Transactional    = "transactional"
+	NonTransactional = "non_transactional"
+	Both             = "both"
+)
+
+// Expand returns the entries to upload for entry, see Both.
+func Expand(entry sp.WritableSuppressionEntry) []sp.WritableSuppressionEntry {
+	if entry.Type != Both {
+		return []sp.WritableSuppressionEntry{entry}
+	}
+
+	transactional, nonTransactional := entry, entry
+	transactional.Type = Transactional
+	nonTransactional.Type = NonTransactional
+	return []sp.WritableSuppressionEntry{transactional, nonTransactional}
+}
+
+// CSVOptions configures how a CSV importer turns rows into entries.
+type CSVOptions struct {
+	// FieldsPerRecord is passed on to encoding/csv: the exact number of fields
+	// of every record, or -1 to accept records of any length.
+	FieldsPerRecord int
+	// Header tells that the first row is a header row, whatever its names.
+	// Otherwise it is only recognised when a column is mapped by name or the
+	// email column is headed like emailHeaders.
+	Header bool
+	// Verbatim keeps the recipients as listed, only trimmed, instead of
+	// normalizing them and skipping the invalid ones.
+	Verbatim bool
+	// Type is used when no type column is mapped or it holds an unknown value.
+	// Default: derived from the reason of the row, see TypeRules.
+	Type string
+	// Description is used when no description column is mapped.
+	Description string
+	// Describe, when set, rewrites the value of the description column.
+	Describe func(value string) string
+	// Filter, when set, returns the reason a data row must be skipped, if any.
+	// It runs before the email address is checked.
+	Filter func(record []string) string
+	// Reason, when set, returns why the provider suppressed the recipient of
+	// a record, one of the Reason constants.
+	Reason func(record []string) string
+}
+
+// emailHeaders are the usual names of the email column, compared regardless
+// of case, spaces, underscores and hyphens.
+var emailHeaders = []string{"email", "email address", "e-mail", "recipient", "address"}
+
+// isEmailHeader reports whether value names the email column.
+func isEmailHeader(value string) bool {
+	for _, name := range emailHeaders {
+		if fieldName(value) == fieldName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// CSV imports entries from a CSV file according to a column mapping.
+type CSV struct {
+	reader  *csv.Reader
+	opts    CSVOptions
+	indexes map[string]int
+	start   Position
+	rows    int64
+	// header is set while a header row known to be there is still to be read.
+	header bool
+}
+
+// CSVSource returns a Source named name reading CSV files with opts, and
+// mapping as the default column mapping.
+func CSVSource(name, mapping string, opts CSVOptions) Source {
+	return Source{
+		Name:    name,
+		Mapping: mapping,
+		New: func(r io.ReadSeeker, start Position, m Mapping) (Importer, error) {
+			return NewCSV(r, start, m, opts)
+		},
+	}
+}
+
+// NewCSV returns an importer reading r from start. When a column is mapped by
+// name the header is read from the beginning of r, even when resuming.
+func NewCSV(r io.ReadSeeker, start Position, mapping Mapping, opts CSVOptions) (*CSV, error) {
+	c := &CSV{opts: opts, indexes: map[string]int{}, start: start, rows: start.Rows}
+
+	var header []string
+	if mapping.byName() {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		hr := csv.NewReader(bufio.NewReader(r))
+		hr.FieldsPerRecord = -1
+
+		var err error
+		if header, err = hr.Read(); err != nil && err != io.EOF {
+			return nil, err
+		}
+	}
+	c.header = (mapping.byName() || opts.Header) && start.Offset == 0
+
+	for field, column := range mapping {
+		if column.Name == "" {
+			c.indexes[field] = column.Index
+			continue
+		}
+
+		index := -1
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), column.Name) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("column '%s' mapped to %s not found in the header row", column.Name, field)
+		}
+		c.indexes[field] = index
+	}
+
+	if _, err := r.Seek(start.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	c.reader = csv.NewReader(bufio.NewReader(r))
+	c.reader.FieldsPerRecord = opts.FieldsPerRecord
+
+	return c, nil
+}
+
+// Read implements Importer.
+func (c *CSV) Read() (*Row, error) {
+	record, err := c.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	c.rows++
+
+	row := &Row{
+		Number: c.rows,
+		Offset: c.start.Offset + c.reader.InputOffset(),
+		Record: record,
+	}
+
+	email := c.field(record, FieldEmail)
+	row.Entry.Recipient = email
+
+	if c.header || isEmailHeader(email) {
+		// Skip over header row
+		c.header = false
+		row.Skip = SkipHeader
+		return row, nil
+	}
+
+	if c.opts.Filter != nil {
+		if row.Skip = c.opts.Filter(record); row.Skip != "" {
+			return row, nil
+		}
+	}
+
+	if c.opts.Verbatim {
+		if email == "" {
+			row.Skip = SkipEmpty
+			return row, nil
+		}
+	} else {
+		normalized, skip := checkAddress(email)
+		if row.Skip = skip; row.Skip != "" {
+			return row, nil
+		}
+		row.Entry.Recipient = normalized
+	}
+
+	row.Entry.Type = c.entryType(record)
+	row.Entry.Description = c.description(record)
+	if c.opts.Reason != nil {
+		row.Reason = c.opts.Reason(record)
+	}
+	return row, nil
+}
+
+// field returns the value of the column mapped to name, or "" when it is not
+// mapped or the record is too short.
+func (c *CSV) field(record []string, name string) string {
+	index, ok := c.indexes[name]
+	if !ok || index >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[index])
+}
+
+func (c *CSV) entryType(record []string) string {
+	switch strings.ToLower(strings.Replace(c.field(record, FieldType), "-", "_", -1)) {
+	case Transactional:
+		return Transactional
+	case NonTransactional:
+		return NonTransactional
+	case Both:
+		return Both
+	}
+	return c.opts.Type
+}
+
+func (c *CSV) description(record []string) string {
+	if _, ok := c.indexes[FieldDescription]; !ok {
+		return c.opts.Description
+	}
+
+	value := c.field(record, FieldDescription)
+	if c.opts.Describe != nil {
+		return c.opts.Describe(value)
+	}
+	return value
+}
@@ -0,0 +1,65 @@
+package importer
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// readCSV returns every row NewCSV reads from input with the recipients in the
+// first column.
+func readCSV(t *testing.T, input string, opts CSVOptions) []*Row {
+	t.Helper()
+	rows, err := NewCSV(strings.NewReader(input), Position{}, Mapping{FieldEmail: {Index: 0}}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var read []*Row
+	for {
+		row, err := rows.Read()
+		if err == io.EOF {
+			return read
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		read = append(read, row)
+	}
+}
+
+func TestCSVHeaderRow(t *testing.T) {
+	for _, header := range []string{"email", "Email", "EMAIL", "Email Address", "email_address", "E-mail", "Recipient", "address"} {
+		checkRows(t, readCSV(t, header+",name\nname@example.com,Name\n", CSVOptions{FieldsPerRecord: -1}), []wantRow{
+			{header, "", "", SkipHeader},
+			{"name@example.com", "", "", ""},
+		})
+	}
+}
+
+func TestCSVHeaderOption(t *testing.T) {
+	input := "Mail,name\nname@example.com,Name\n"
+	checkRows(t, readCSV(t, input, CSVOptions{FieldsPerRecord: -1}), []wantRow{
+		{"Mail", "", "", SkipInvalid},
+		{"name@example.com", "", "", ""},
+	})
+	checkRows(t, readCSV(t, input, CSVOptions{FieldsPerRecord: -1, Header: true}), []wantRow{
+		{"Mail", "", "", SkipHeader},
+		{"name@example.com", "", "", ""},
+	})
+}
+
+func TestCSVVerbatim(t *testing.T) {
+	input := "email\n Name@Example.COM \n'bad@example\n\nmailto:name@example.com\n"
+	checkRows(t, readCSV(t, input, CSVOptions{FieldsPerRecord: -1, Verbatim: true}), []wantRow{
+		{"email", "", "", SkipHeader},
+		{"Name@Example.COM", "", "", ""},
+		{"'bad@example", "", "", ""},
+		{"mailto:name@example.com", "", "", ""},
+	})
+	checkRows(t, readCSV(t, input, CSVOptions{FieldsPerRecord: -1}), []wantRow{
+		{"email", "", "", SkipHeader},
+		{"Name@example.com", "", "", ""},
+		{"'bad@example", "", "", "missing_tld"},
+		{"name@example.com", "", "", ""},
+	})
+}
@@ -0,0 +1,241 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	sp "github.com/SparkPost/gosparkpost"
+
+	"github.com/SparkPost/sparkpost-cli/internal/validate"
+)
+
+// Reasons a row is not imported, as reported by `--dry-run` and written to `--rejects`.
+const (
+	SkipHeader = "header"
+	// SkipEmpty and SkipInvalid are the most common of the validate reasons,
+	// which every row with an address that cannot be imported is skipped for.
+	SkipEmpty      = validate.ReasonEmpty
+	SkipInvalid    = validate.ReasonSyntax
+	SkipSoftBounce = "soft_bounce"
+	// SkipNotSuppressed marks rows whose status does not call for a suppression.
+	SkipNotSuppressed = "not_suppressed"
+	// SkipUnrecognized marks rows whose kind of suppression cannot be told.
+	SkipUnrecognized = "unrecognized"
+	// SkipDuplicate is set by the import pipeline, which sees the rows of every batch.
+	SkipDuplicate = "duplicate"
+)
+
+// Reasons providers give for suppressing a recipient, as set in Row.Reason.
+const (
+	ReasonHardBounce    = "hard_bounce"
+	ReasonSpamComplaint = "spam_complaint"
+	ReasonBlocked       = "blocked"
+	ReasonUnsubscribe   = "unsubscribe"
+	ReasonManual        = "manual"
+)
+
+// Fields of a suppression entry that can be mapped to input columns.
+const (
+	FieldEmail       = "email"
+	FieldType        = "type"
+	FieldDescription = "description"
+)
+
+// Position locates a row in the input: Rows records were read before it and
+// it starts Offset bytes into the file.
+type Position struct {
+	Rows   int64 `json:"rows"`
+	Offset int64 `json:"offset"`
+}
+
+// Row is a record read by an Importer.
+type Row struct {
+	// Number is the 1-based number of the record in the file.
+	Number int64
+	// Offset is the byte offset just after the record.
+	Offset int64
+	// Record holds the fields of the record as read, for the `--rejects` file.
+	Record []string
+	// Entry is the suppression entry built from the record. Its Recipient is
+	// set even when the row is skipped. Its Type is empty unless the record
+	// gives one, TypeRules derive it from Reason otherwise.
+	Entry sp.WritableSuppressionEntry
+	// Reason is why the provider suppressed the recipient, one of the Reason
+	// constants, or empty when the export does not tell.
+	Reason string
+	// Skip is the reason the row must not be imported, empty otherwise.
+	Skip string
+}
+
+// Importer reads suppression entries from the export of another provider.
+type Importer interface {
+	// Read returns the next row, or io.EOF after the last one.
+	Read() (*Row, error)
+}
+
+// Source describes a provider whose exports can be imported.
+type Source struct {
+	// Name is the name of the import command.
+	Name string
+	// Mapping is the column mapping used when none is given with `--map`.
+	Mapping string
+	// New returns an Importer reading r from start with the given column mapping.
+	New func(r io.ReadSeeker, start Position, mapping Mapping) (Importer, error)
+}
+
+// Column designates an input column by its index, counting from 0, or by its
+// name in the header row when Name is set.
+type Column struct {
+	Index int
+	Name  string
+}
+
+func (c Column) String() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return strconv.Itoa(c.Index)
+}
+
+// Mapping tells which column holds each field of the suppression entries.
+type Mapping map[string]Column
+
+// ParseMapping parses a mapping spec such as `email=0,type=reason,description=detail`.
+// Every field is given as field=column where the column is an index or a header
+// name; the email field is required.
+func ParseMapping(spec string) (Mapping, error) {
+	mapping := Mapping{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[1]) == "" {
+			return nil, fmt.Errorf("invalid column mapping '%s', expected field=column", part)
+		}
+		field, column := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+
+		switch field {
+		case FieldEmail, FieldType, FieldDescription:
+		default:
+			return nil, fmt.Errorf("unknown field '%s' in column mapping, expected one of %s, %s, %s", field, FieldEmail, FieldType, FieldDescription)
+		}
+
+		if index, err := strconv.Atoi(column); err == nil {
+			if index < 0 {
+				return nil, fmt.Errorf("invalid column index %d for field '%s'", index, field)
+			}
+			mapping[field] = Column{Index: index}
+		} else {
+			mapping[field] = Column{Name: column}
+		}
+	}
+
+	if _, ok := mapping[FieldEmail]; !ok {
+		return nil, fmt.Errorf("column mapping '%s' does not map the %s field", spec, FieldEmail)
+	}
+	return mapping, nil
+}
+
+// TypeRules gives the type of the entries suppressed for each reason.
+type TypeRules map[string]string
+
+// DefaultTypeRules suppresses the addresses that cannot receive mail, or whose
+// owner complained, for every message. Unsubscribes and manual suppressions
+// only apply to non_transactional messages, so receipts and password resets
+// keep being delivered.
+var DefaultTypeRules = TypeRules{
+	ReasonHardBounce:    Both,
+	ReasonSpamComplaint: Both,
+	ReasonBlocked:       Both,
+	ReasonUnsubscribe:   NonTransactional,
+	ReasonManual:        NonTransactional,
+}
+
+// ParseType checks that value is a type that can be given to an entry.
+func ParseType(value string) (string, error) {
+	switch value {
+	case Transactional, NonTransactional, Both:
+		return value, nil
+	}
+	return "", fmt.Errorf("unknown type '%s', expected one of %s, %s, %s", value, Transactional, NonTransactional, Both)
+}
+
+// ParseTypeRules returns DefaultTypeRules overridden by spec, such as
+// `spam_complaint=non_transactional,manual=both`.
+func ParseTypeRules(spec string) (TypeRules, error) {
+	rules := TypeRules{}
+	for reason, typ := range DefaultTypeRules {
+		rules[reason] = typ
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid type rule '%s', expected reason=type", part)
+		}
+		reason := strings.TrimSpace(kv[0])
+		if _, ok := DefaultTypeRules[reason]; !ok {
+			return nil, fmt.Errorf("unknown reason '%s' in type rule, expected one of %s", reason, strings.Join(reasons(), ", "))
+		}
+		typ, err := ParseType(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, err
+		}
+		rules[reason] = typ
+	}
+	return rules, nil
+}
+
+// reasons returns the reasons of DefaultTypeRules, sorted.
+func reasons() []string {
+	names := make([]string, 0, len(DefaultTypeRules))
+	for reason := range DefaultTypeRules {
+		names = append(names, reason)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply sets the type of the entry of row from its reason, unless the record
+// gave one. Entries without a known reason are non_transactional.
+func (r TypeRules) Apply(row *Row) {
+	if row.Entry.Type != "" {
+		return
+	}
+	if typ, ok := r[row.Reason]; ok {
+		row.Entry.Type = typ
+		return
+	}
+	row.Entry.Type = NonTransactional
+}
+
+// checkAddress returns recipient normalized, or the reason it cannot be
+// imported, see validate.Normalize.
+func checkAddress(recipient string) (string, string) {
+	normalized, err := validate.Normalize(recipient)
+	if err != nil {
+		return "", err.(*validate.Error).Reason
+	}
+	return normalized, ""
+}
+
+// byName reports whether a column of m is designated by its header name.
+func (m Mapping) byName() bool {
+	for _, column := range m {
+		if column.Name != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package importer
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// wantRow is the expected outcome of a row read by a Source.
+type wantRow struct {
+	recipient   string
+	reason      string
+	description string
+	skip        string
+}
+
+// openTestdata opens the fixture name of the testdata directory.
+func openTestdata(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+// readRows returns every row source reads from r, starting at start.
+func readRows(t *testing.T, source Source, r io.ReadSeeker, start Position) []*Row {
+	t.Helper()
+	rows, err := source.New(r, start, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var read []*Row
+	for {
+		row, err := rows.Read()
+		if err == io.EOF {
+			return read
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		read = append(read, row)
+	}
+}
+
+// checkRows compares rows with want, reporting every difference.
+func checkRows(t *testing.T, rows []*Row, want []wantRow) {
+	t.Helper()
+	if len(rows) != len(want) {
+		t.Errorf("read %d rows, want %d", len(rows), len(want))
+	}
+	for i := 0; i < len(rows) && i < len(want); i++ {
+		got := wantRow{rows[i].Entry.Recipient, rows[i].Reason, rows[i].Entry.Description, rows[i].Skip}
+		if want[i].skip != "" {
+			// Only the recipient and the reason to skip matter for a skipped row.
+			got.reason, got.description = want[i].reason, want[i].description
+		}
+		if got != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i+1, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package importer
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Stdin is the `--file` value standing for the standard input.
+const Stdin = "-"
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// Input is one stream of an import: a file, a member of a zip archive or the
+// standard input.
+type Input struct {
+	// Path is the file read, Stdin for the standard input.
+	Path string
+	// Member is the name of the zip archive member read, if any.
+	Member string
+}
+
+func (in Input) String() string {
+	switch {
+	case in.Path == Stdin:
+		return "<stdin>"
+	case in.Member != "":
+		return in.Path + ":" + in.Member
+	}
+	return in.Path
+}
+
+// CheckpointPath returns the default checkpoint location of in, empty for the
+// standard input which cannot be read again.
+func (in Input) CheckpointPath() string {
+	switch {
+	case in.Path == Stdin:
+		return ""
+	case in.Member != "":
+		return CheckpointPath(in.Path + "." + strings.NewReplacer("/", "_", "\\", "_").Replace(in.Member))
+	}
+	return CheckpointPath(in.Path)
+}
+
+// ExpandInputs returns the inputs named by the `--file` values args. Shell
+// style glob patterns are expanded, zip archives stand for every file they
+// hold, and Stdin for the standard input. Every file is checked, so that an
+// import never stops part way through on a missing one.
+func ExpandInputs(args []string) ([]Input, error) {
+	var inputs []Input
+	stdin := false
+	for _, arg := range args {
+		if arg == Stdin {
+			if stdin {
+				return nil, fmt.Errorf("the standard input can only be read once")
+			}
+			stdin = true
+			inputs = append(inputs, Input{Path: Stdin})
+			continue
+		}
+
+		paths := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern '%s': %s", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no file matches '%s'", arg)
+			}
+			paths = matches
+		}
+
+		for _, p := range paths {
+			expanded, err := expandPath(p)
+			if err != nil {
+				return nil, err
+			}
+			inputs = append(inputs, expanded...)
+		}
+	}
+	return inputs, nil
+}
+
+// expandPath returns the inputs of the file at p, one per member of a zip archive.
+func expandPath(p string) ([]Input, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, openError(p, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("cannot import '%s': it is a directory", p)
+	}
+	if !strings.EqualFold(filepath.Ext(p), ".zip") {
+		return []Input{{Path: p}}, nil
+	}
+
+	archive, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read zip archive '%s': %s", p, err)
+	}
+	defer archive.Close()
+
+	var inputs []Input
+	for _, member := range archive.File {
+		name := member.Name
+		if member.FileInfo().IsDir() || strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".") {
+			continue
+		}
+		inputs = append(inputs, Input{Path: p, Member: name})
+	}
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("zip archive '%s' holds no file", p)
+	}
+	return inputs, nil
+}
+
+// File is an opened Input. Inputs that cannot be read directly, because they
+// are compressed or come from the standard input, are copied to a temporary
+// file first, so that every importer can seek in them.
+type File struct {
+	*os.File
+	temporary bool
+}
+
+// Open opens in, decompressing gzip data and zip archive members.
+func (in Input) Open() (*File, error) {
+	switch {
+	case in.Path == Stdin:
+		return spool(os.Stdin, in)
+	case in.Member != "":
+		return in.openMember()
+	}
+
+	f, err := os.Open(in.Path)
+	if err != nil {
+		return nil, openError(in.Path, err)
+	}
+	magic := make([]byte, len(gzipMagic))
+	n, _ := io.ReadFull(f, magic)
+	if !bytes.Equal(magic[:n], gzipMagic) {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close()
+			return nil, openError(in.Path, err)
+		}
+		return &File{File: f}, nil
+	}
+
+	defer f.Close()
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, openError(in.Path, err)
+	}
+	return spool(f, in)
+}
+
+// openMember returns the decompressed member of the zip archive of in.
+func (in Input) openMember() (*File, error) {
+	archive, err := zip.OpenReader(in.Path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read zip archive '%s': %s", in.Path, err)
+	}
+	defer archive.Close()
+
+	for _, member := range archive.File {
+		if member.Name != in.Member {
+			continue
+		}
+		r, err := member.Open()
+		if err != nil {
+			return nil, fmt.Errorf("cannot read '%s': %s", in, err)
+		}
+		defer r.Close()
+		return spool(r, in)
+	}
+	return nil, fmt.Errorf("cannot read '%s': no such member in the archive", in)
+}
+
+// spool copies r to a temporary file, decompressing it when gzip compressed.
+func spool(r io.Reader, in Input) (*File, error) {
+	buffered := bufio.NewReader(r)
+	magic, _ := buffered.Peek(len(zipMagic))
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decompress '%s': %s", in, err)
+		}
+		defer gz.Close()
+		r = gz
+	case in.Path == Stdin && bytes.Equal(magic, zipMagic):
+		return nil, fmt.Errorf("zip archives cannot be read from the standard input, give the archive path instead")
+	default:
+		r = buffered
+	}
+
+	tmp, err := ioutil.TempFile("", "sparkpost-import-")
+	if err != nil {
+		return nil, err
+	}
+	f := &File{File: tmp, temporary: true}
+	if _, err := io.Copy(tmp, r); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("cannot read '%s': %s", in, err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// Close closes f, removing it when temporary.
+func (f *File) Close() error {
+	err := f.File.Close()
+	if f.temporary {
+		if rmErr := os.Remove(f.Name()); err == nil {
+			err = rmErr
+		}
+	}
+	return err
+}
+
+// openError returns a readable error for the failure to open the file at p.
+func openError(p string, err error) error {
+	if pathErr, ok := err.(*os.PathError); ok {
+		err = pathErr.Err
+	}
+	switch {
+	case os.IsNotExist(err):
+		return fmt.Errorf("cannot open '%s': no such file", p)
+	case os.IsPermission(err):
+		return fmt.Errorf("cannot open '%s': permission denied", p)
+	}
+	return fmt.Errorf("cannot open '%s': %s", p, err)
+}
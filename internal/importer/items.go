@@ -0,0 +1,355 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Item is a record whose fields are known by name: a JSON object, or a CSV
+// row keyed by the names of the header row.
+type Item map[string]interface{}
+
+// String returns the field key as text. Lists are joined with commas.
+func (i Item) String(key string) string {
+	switch v := i[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case []interface{}:
+		values := make([]string, len(v))
+		for n := range v {
+			values[n] = Item{"v": v[n]}.String("v")
+		}
+		return strings.Join(values, ",")
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Lookup returns the first of the fields names that is set, as text. Names
+// match keys regardless of case, spaces, underscores and hyphens, so
+// "email_address" finds both "Email Address" and "email_address".
+func (i Item) Lookup(names ...string) string {
+	for _, name := range names {
+		if v := i.String(name); v != "" {
+			return v
+		}
+		for key := range i {
+			if fieldName(key) == fieldName(name) {
+				if v := i.String(key); v != "" {
+					return v
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// Has reports whether item has one of the fields names, even empty. Names
+// match keys as in Lookup.
+func (i Item) Has(names ...string) bool {
+	for key := range i {
+		for _, name := range names {
+			if fieldName(key) == fieldName(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Only reports whether every field of item is one of names.
+func (i Item) Only(names ...string) bool {
+	for key := range i {
+		if !(Item{key: nil}).Has(names...) {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldName normalizes a field name for Lookup.
+func fieldName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '_', '-':
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
+
+// ItemOptions configures how an item importer turns items into entries.
+type ItemOptions struct {
+	// Keys lists the object keys that may hold the array of items in JSON
+	// exports wrapping them in an object. A top-level array is always accepted.
+	Keys []string
+	// Entry fills the entry of row from item, along with the reason the
+	// recipient was suppressed and the reason the row must be skipped, if any.
+	// Entries without a valid email address are skipped afterwards.
+	Entry func(item Item, row *Row)
+}
+
+// ItemSource returns a Source named name reading exports of items with opts.
+// Its commands do not accept a column mapping.
+func ItemSource(name string, opts ItemOptions) Source {
+	return Source{
+		Name: name,
+		New: func(r io.ReadSeeker, start Position, m Mapping) (Importer, error) {
+			if m != nil {
+				return nil, fmt.Errorf("the %s export format is fixed, --map is not supported", name)
+			}
+			return NewItems(r, start, opts)
+		},
+	}
+}
+
+// NewItems returns an importer reading the items of r from start. The input
+// is read as JSON when it starts with an object or an array, as CSV with a
+// header row otherwise. JSON input is resumed by skipping start.Rows items.
+func NewItems(r io.ReadSeeker, start Position, opts ItemOptions) (Importer, error) {
+	isJSON, err := startsWithJSON(r)
+	if err != nil {
+		return nil, err
+	}
+	if isJSON {
+		return newJSONItems(r, start, opts)
+	}
+	return newCSVItems(r, start, opts)
+}
+
+// startsWithJSON reports whether the first significant character of r opens
+// a JSON object or array, leaving r at its beginning.
+func startsWithJSON(r io.ReadSeeker) (bool, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	head = bytes.TrimLeft(bytes.TrimPrefix(head[:n], []byte("\xef\xbb\xbf")), " \t\r\n")
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	return len(head) > 0 && (head[0] == '{' || head[0] == '['), nil
+}
+
+// itemRow builds the row of item, applying opts.Entry and the address checks.
+func itemRow(number, offset int64, record []string, item Item, opts ItemOptions) *Row {
+	row := &Row{Number: number, Offset: offset, Record: record}
+	opts.Entry(item, row)
+	if row.Skip != "" {
+		return row
+	}
+
+	if normalized, skip := checkAddress(row.Entry.Recipient); skip != "" {
+		row.Skip = skip
+	} else {
+		row.Entry.Recipient = normalized
+	}
+	return row
+}
+
+// jsonItems streams the items of a JSON array, or of several documents
+// concatenated in the same input such as the pages of a paginated export.
+type jsonItems struct {
+	dec  *json.Decoder
+	opts ItemOptions
+	rows int64
+	// wrapped is set when the current array is held by an object.
+	wrapped bool
+}
+
+func newJSONItems(r io.Reader, start Position, opts ItemOptions) (*jsonItems, error) {
+	j := &jsonItems{dec: json.NewDecoder(bufio.NewReader(r)), opts: opts}
+	if err := j.openArray(); err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("expected a JSON object or array of items")
+		}
+		return nil, err
+	}
+
+	for j.rows < start.Rows {
+		if _, err := j.next(); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// openArray moves the decoder into the array of items of the next document.
+// It returns io.EOF when there is no other document.
+func (j *jsonItems) openArray() error {
+	tok, err := j.dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == json.Delim('[') {
+		j.wrapped = false
+		return nil
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("expected a JSON object or array of items")
+	}
+
+	j.wrapped = true
+	for j.dec.More() {
+		tok, err := j.dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+
+		for _, k := range j.opts.Keys {
+			if k == key {
+				if tok, err = j.dec.Token(); err != nil {
+					return err
+				}
+				if tok != json.Delim('[') {
+					return fmt.Errorf("expected an array of items in '%s'", key)
+				}
+				return nil
+			}
+		}
+
+		var skip json.RawMessage
+		if err := j.dec.Decode(&skip); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("no array of items found, expected one of the keys %s", strings.Join(j.opts.Keys, ", "))
+}
+
+// closeArray consumes the end of the current array and of the object holding it.
+func (j *jsonItems) closeArray() error {
+	if _, err := j.dec.Token(); err != nil {
+		return err
+	}
+	if !j.wrapped {
+		return nil
+	}
+
+	for j.dec.More() {
+		var skip json.RawMessage
+		if _, err := j.dec.Token(); err != nil {
+			return err
+		}
+		if err := j.dec.Decode(&skip); err != nil {
+			return err
+		}
+	}
+	_, err := j.dec.Token()
+	return err
+}
+
+// next returns the next item, moving on to the following documents as needed.
+func (j *jsonItems) next() (json.RawMessage, error) {
+	for !j.dec.More() {
+		if err := j.closeArray(); err != nil {
+			return nil, err
+		}
+		if err := j.openArray(); err != nil {
+			return nil, err
+		}
+	}
+
+	var raw json.RawMessage
+	if err := j.dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	j.rows++
+	return raw, nil
+}
+
+func (j *jsonItems) Read() (*Row, error) {
+	raw, err := j.next()
+	if err != nil {
+		return nil, err
+	}
+
+	item := Item{}
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return nil, fmt.Errorf("item %d: %s", j.rows, err)
+	}
+	var record bytes.Buffer
+	if err := json.Compact(&record, raw); err != nil {
+		return nil, fmt.Errorf("item %d: %s", j.rows, err)
+	}
+	return itemRow(j.rows, 0, []string{record.String()}, item, j.opts), nil
+}
+
+// csvItems reads the rows of a CSV file with a header row as items.
+type csvItems struct {
+	reader *csv.Reader
+	header []string
+	opts   ItemOptions
+	start  Position
+	rows   int64
+	// skipHeader is set while the header row is still to be read.
+	skipHeader bool
+}
+
+func newCSVItems(r io.ReadSeeker, start Position, opts ItemOptions) (*csvItems, error) {
+	hr := csv.NewReader(bufio.NewReader(r))
+	hr.FieldsPerRecord = -1
+	header, err := hr.Read()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\xef\xbb\xbf"))
+	}
+
+	if _, err := r.Seek(start.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.FieldsPerRecord = -1
+
+	return &csvItems{
+		reader:     reader,
+		header:     header,
+		opts:       opts,
+		start:      start,
+		rows:       start.Rows,
+		skipHeader: start.Offset == 0,
+	}, nil
+}
+
+func (c *csvItems) Read() (*Row, error) {
+	record, err := c.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	c.rows++
+	offset := c.start.Offset + c.reader.InputOffset()
+
+	if c.skipHeader {
+		c.skipHeader = false
+		return &Row{Number: c.rows, Offset: offset, Record: record, Skip: SkipHeader}, nil
+	}
+
+	item := Item{}
+	for i, name := range c.header {
+		if i < len(record) {
+			item[name] = record[i]
+		}
+	}
+	return itemRow(c.rows, offset, record, item, c.opts), nil
+}
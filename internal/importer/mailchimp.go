@@ -0,0 +1,41 @@
+package importer
+
+import "strings"
+
+// Mailchimp imports the members of a Mailchimp audience, keeping those that
+// were cleaned or unsubscribed. It reads the CSV files of an audience export,
+// where the status of the members is told by the CLEAN_TIME and UNSUB_TIME
+// columns, and the JSON returned by the members API, which has a status field.
+var Mailchimp = ItemSource("mailchimp", ItemOptions{
+	Keys:  []string{"members"},
+	Entry: mailchimpEntry,
+})
+
+func mailchimpEntry(item Item, row *Row) {
+	row.Entry.Recipient = item.Lookup("email address")
+
+	status := strings.ToLower(item.Lookup("status"))
+	if status == "" {
+		switch {
+		case item.Lookup("clean time") != "":
+			status = "cleaned"
+		case item.Lookup("unsub time") != "":
+			status = "unsubscribed"
+		}
+	}
+
+	switch status {
+	case "cleaned":
+		// Mailchimp cleans the members whose address hard bounced.
+		row.Reason = ReasonHardBounce
+		row.Entry.Description = "MCB: cleaned"
+	case "unsubscribed":
+		row.Reason = ReasonUnsubscribe
+		row.Entry.Description = "MCU: unsubscribed"
+		if reason := item.Lookup("unsub reason", "unsubscribe reason"); reason != "" {
+			row.Entry.Description += ": " + reason
+		}
+	default:
+		row.Skip = SkipNotSuppressed
+	}
+}
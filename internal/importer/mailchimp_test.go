@@ -0,0 +1,30 @@
+package importer
+
+import "testing"
+
+func TestMailchimp(t *testing.T) {
+	tests := []struct {
+		file string
+		want []wantRow
+	}{
+		{"mailchimp-cleaned.csv", []wantRow{
+			{"", "", "", SkipHeader},
+			{"cleaned@example.com", ReasonHardBounce, "MCB: cleaned", ""},
+		}},
+		{"mailchimp-unsubscribed.csv", []wantRow{
+			{"", "", "", SkipHeader},
+			{"unsubscribed@example.com", ReasonUnsubscribe, "MCU: unsubscribed: No longer interested", ""},
+		}},
+		{"mailchimp-members.json", []wantRow{
+			{"cleaned-api@example.com", ReasonHardBounce, "MCB: cleaned", ""},
+			{"unsubscribed-api@example.com", ReasonUnsubscribe, "MCU: unsubscribed: N/A (Unsubscribed by admin)", ""},
+			{"subscribed@example.com", "", "", SkipNotSuppressed},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			checkRows(t, readRows(t, Mailchimp, openTestdata(t, test.file), Position{}), test.want)
+		})
+	}
+}
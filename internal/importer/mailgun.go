@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kinds of Mailgun suppressions, as named by its suppressions API.
+const (
+	MailgunBounces      = "bounces"
+	MailgunUnsubscribes = "unsubscribes"
+	MailgunComplaints   = "complaints"
+)
+
+// MailgunKinds lists the kinds of Mailgun suppressions.
+var MailgunKinds = []string{MailgunBounces, MailgunUnsubscribes, MailgunComplaints}
+
+// mailgunComplaintFields are the only fields of Mailgun complaints.
+var mailgunComplaintFields = []string{"address", "created_at", "count"}
+
+// Mailgun imports the bounces, unsubscribes and complaints exported from
+// Mailgun, as CSV or as the JSON returned by its suppressions API. The kind of
+// each item is recognised by its fields: bounces have a code or an error,
+// unsubscribes have tags and complaints have nothing but an address and a
+// creation date. Items of any other shape are skipped as unrecognized.
+var Mailgun = mailgunSource("")
+
+// MailgunKind returns the Mailgun source importing every item as kind, one of
+// MailgunKinds, or recognising the kind of each item when kind is empty.
+func MailgunKind(kind string) (Source, error) {
+	if kind == "" {
+		return Mailgun, nil
+	}
+	for _, k := range MailgunKinds {
+		if k == kind {
+			return mailgunSource(kind), nil
+		}
+	}
+	return Source{}, fmt.Errorf("unknown Mailgun kind '%s', expected one of %s", kind, strings.Join(MailgunKinds, ", "))
+}
+
+func mailgunSource(kind string) Source {
+	return ItemSource("mailgun", ItemOptions{
+		Keys: []string{"items"},
+		Entry: func(item Item, row *Row) {
+			mailgunEntry(kind, item, row)
+		},
+	})
+}
+
+func mailgunEntry(kind string, item Item, row *Row) {
+	row.Entry.Recipient = item.String("address")
+
+	if kind == "" {
+		kind = mailgunKindOf(item)
+	}
+	switch kind {
+	case MailgunBounces:
+		row.Reason = ReasonHardBounce
+		row.Entry.Description = strings.TrimSpace(fmt.Sprintf("MGB: %s %s", item.String("code"), item.String("error")))
+	case MailgunUnsubscribes:
+		row.Reason = ReasonUnsubscribe
+		row.Entry.Description = "MGU: unsubscribed"
+		if tags := item.Lookup("tags", "tag"); tags != "" && tags != "*" {
+			row.Entry.Description += " from " + tags
+		}
+	case MailgunComplaints:
+		row.Reason = ReasonSpamComplaint
+		row.Entry.Description = "MGC: spam complaint"
+	default:
+		row.Skip = SkipUnrecognized
+	}
+}
+
+// mailgunKindOf recognises the kind of item by its fields, empty when it has
+// the fields of none.
+func mailgunKindOf(item Item) string {
+	switch {
+	case item.Has("code", "error"):
+		return MailgunBounces
+	case item.Has("tags", "tag"):
+		return MailgunUnsubscribes
+	case item.Has("address") && item.Only(mailgunComplaintFields...):
+		return MailgunComplaints
+	}
+	return ""
+}
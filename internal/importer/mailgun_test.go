@@ -0,0 +1,57 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMailgunKinds(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   string
+		export string
+		want   []wantRow
+	}{
+		{"bounces", "", `{"items": [
+			{"address": "bounced@example.com", "code": "550", "error": "No such mailbox", "created_at": "Mon, 11 Apr 2016 20:15:55 UTC"},
+			{"address": "empty@example.com", "code": "", "error": "", "created_at": "Mon, 11 Apr 2016 20:15:55 UTC"}]}`,
+			[]wantRow{
+				{"bounced@example.com", ReasonHardBounce, "MGB: 550 No such mailbox", ""},
+				{"empty@example.com", ReasonHardBounce, "MGB:", ""},
+			}},
+		{"unsubscribes", "", "address,tags,created_at\nall@example.com,*,2016-04-11\nnews@example.com,news,2016-04-11\n",
+			[]wantRow{
+				{"", "", "", SkipHeader},
+				{"all@example.com", ReasonUnsubscribe, "MGU: unsubscribed", ""},
+				{"news@example.com", ReasonUnsubscribe, "MGU: unsubscribed from news", ""},
+			}},
+		{"complaints", "", `[{"address": "complained@example.com", "created_at": "Mon, 11 Apr 2016 20:15:55 UTC"}]`,
+			[]wantRow{
+				{"complained@example.com", ReasonSpamComplaint, "MGC: spam complaint", ""},
+			}},
+		{"unrecognized", "", "address,reason,created_at\nother@example.com,manual,2016-04-11\n",
+			[]wantRow{
+				{"", "", "", SkipHeader},
+				{"other@example.com", "", "", SkipUnrecognized},
+			}},
+		{"given kind", MailgunUnsubscribes, "address,reason,created_at\nother@example.com,manual,2016-04-11\n",
+			[]wantRow{
+				{"", "", "", SkipHeader},
+				{"other@example.com", ReasonUnsubscribe, "MGU: unsubscribed", ""},
+			}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			source, err := MailgunKind(test.kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkRows(t, readRows(t, source, strings.NewReader(test.export), Position{}), test.want)
+		})
+	}
+
+	if _, err := MailgunKind("bounce"); err == nil {
+		t.Error("MailgunKind accepted an unknown kind")
+	}
+}
@@ -0,0 +1,26 @@
+package importer
+
+import "strings"
+
+// Mailjet imports the contacts exported from Mailjet as CSV, keeping those
+// whose status is blocked or unsubscribed.
+var Mailjet = ItemSource("mailjet", ItemOptions{
+	Keys:  []string{"Data"},
+	Entry: mailjetEntry,
+})
+
+func mailjetEntry(item Item, row *Row) {
+	row.Entry.Recipient = item.Lookup("email", "email address")
+
+	switch strings.ToLower(item.Lookup("status")) {
+	case "blocked":
+		// Mailjet does not send any message to a blocked contact.
+		row.Reason = ReasonBlocked
+		row.Entry.Description = "MJB: blocked"
+	case "unsub", "unsubscribed":
+		row.Reason = ReasonUnsubscribe
+		row.Entry.Description = "MJU: unsubscribed"
+	default:
+		row.Skip = SkipNotSuppressed
+	}
+}
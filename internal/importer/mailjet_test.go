@@ -0,0 +1,12 @@
+package importer
+
+import "testing"
+
+func TestMailjet(t *testing.T) {
+	checkRows(t, readRows(t, Mailjet, openTestdata(t, "mailjet-contacts.csv"), Position{}), []wantRow{
+		{"", "", "", SkipHeader},
+		{"blocked@example.com", ReasonBlocked, "MJB: blocked", ""},
+		{"unsub@example.com", ReasonUnsubscribe, "MJU: unsubscribed", ""},
+		{"active@example.com", "", "", SkipNotSuppressed},
+	})
+}
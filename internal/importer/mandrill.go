@@ -0,0 +1,45 @@
+package importer
+
+import "fmt"
+
+// Column mapping for Mandrill Blacklist
+const (
+	MandrillEmailCol      = 0
+	MandrillReasonCol     = 1
+	MandrillDetailCol     = 2
+	MandrillCreatedCol    = 3
+	MandrillExpiresAtCol  = 4
+	MandrillLastEventCol  = 5
+	MandrillExpiresAt2Col = 6
+	MandrillSubAccountCol = 7
+)
+
+// Mandrill imports the hard bounces, spam complaints, unsubscribes and manual
+// entries of a Mandrill blacklist export, skipping its soft bounces.
+// See https://mandrill.zendesk.com/hc/en-us/articles/205582997
+var Mandrill = CSVSource("mandrill", fmt.Sprintf("email=%d,description=%d", MandrillEmailCol, MandrillDetailCol), CSVOptions{
+	FieldsPerRecord: 8,
+	Describe: func(detail string) string {
+		return fmt.Sprintf("MBL: %s", detail)
+	},
+	Filter: func(record []string) string {
+		switch record[MandrillReasonCol] {
+		case "hard-bounce", "spam", "unsub", "custom":
+			return ""
+		case "soft-bounce":
+			return SkipSoftBounce
+		}
+		return SkipNotSuppressed
+	},
+	Reason: func(record []string) string {
+		switch record[MandrillReasonCol] {
+		case "hard-bounce":
+			return ReasonHardBounce
+		case "spam":
+			return ReasonSpamComplaint
+		case "unsub":
+			return ReasonUnsubscribe
+		}
+		return ReasonManual
+	},
+})
@@ -0,0 +1,47 @@
+package importer
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+const mandrillExport = `email,reason,detail,created_at,expires_at,last_event_at,expires_at,subaccount
+hard@example.com,hard-bounce,bad mailbox,2016-01-04 10:20:31,,2016-01-04 10:20:31,,
+soft@example.com,soft-bounce,mailbox full,2016-01-04 10:20:31,2016-01-11 10:20:31,2016-01-04 10:20:31,,
+spam@example.com,spam,spam complaint,2016-01-05 08:00:00,,2016-01-05 08:00:00,,
+unsub@example.com,unsub,unsubscribed,2016-01-06 09:00:00,,2016-01-06 09:00:00,,
+custom@example.com,custom,added manually,2016-01-07 11:00:00,,2016-01-07 11:00:00,,
+other@example.com,whitelist,unknown,2016-01-08 12:00:00,,2016-01-08 12:00:00,,
+`
+
+func TestMandrill(t *testing.T) {
+	mapping, err := ParseMapping(Mandrill.Mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := Mandrill.New(strings.NewReader(mandrillExport), Position{}, mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var read []*Row
+	for {
+		row, err := rows.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		read = append(read, row)
+	}
+	checkRows(t, read, []wantRow{
+		{"email", "", "", SkipHeader},
+		{"hard@example.com", ReasonHardBounce, "MBL: bad mailbox", ""},
+		{"soft@example.com", "", "", SkipSoftBounce},
+		{"spam@example.com", ReasonSpamComplaint, "MBL: spam complaint", ""},
+		{"unsub@example.com", ReasonUnsubscribe, "MBL: unsubscribed", ""},
+		{"custom@example.com", ReasonManual, "MBL: added manually", ""},
+		{"other@example.com", "", "", SkipNotSuppressed},
+	})
+}
@@ -0,0 +1,31 @@
+package importer
+
+import "strings"
+
+// Postmark imports the suppressions of a Postmark message stream, as returned
+// by its suppressions API (`GET /message-streams/STREAM/suppressions/dump`).
+var Postmark = ItemSource("postmark", ItemOptions{
+	Keys:  []string{"Suppressions"},
+	Entry: postmarkEntry,
+})
+
+func postmarkEntry(item Item, row *Row) {
+	row.Entry.Recipient = item.Lookup("EmailAddress")
+
+	switch strings.ToLower(item.Lookup("SuppressionReason")) {
+	case "hardbounce":
+		row.Reason = ReasonHardBounce
+		row.Entry.Description = "PMB: hard bounce"
+	case "spamcomplaint":
+		row.Reason = ReasonSpamComplaint
+		row.Entry.Description = "PMC: spam complaint"
+	case "manualsuppression":
+		row.Reason = ReasonManual
+		row.Entry.Description = "PMU: manual suppression"
+		if origin := item.Lookup("Origin"); origin != "" {
+			row.Entry.Description += " by " + strings.ToLower(origin)
+		}
+	default:
+		row.Skip = SkipNotSuppressed
+	}
+}
@@ -0,0 +1,11 @@
+package importer
+
+import "testing"
+
+func TestPostmark(t *testing.T) {
+	checkRows(t, readRows(t, Postmark, openTestdata(t, "postmark-suppressions.json"), Position{}), []wantRow{
+		{"hard-bounce@example.com", ReasonHardBounce, "PMB: hard bounce", ""},
+		{"complaint@example.com", ReasonSpamComplaint, "PMC: spam complaint", ""},
+		{"manual@example.com", ReasonManual, "PMU: manual suppression by customer", ""},
+	})
+}
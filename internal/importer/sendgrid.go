@@ -0,0 +1,17 @@
+package importer
+
+import "fmt"
+
+// Column mapping for SendGrid Blacklist
+const (
+	SendgridEmailCol = 0
+	SendgridCreated  = 1
+)
+
+// SendGrid imports a SendGrid suppression export: bounces, invalid emails,
+// spam reports, unsubscribes or blocks. Every export starts with the email
+// column, any other column is ignored.
+var SendGrid = CSVSource("sendgrid", fmt.Sprintf("email=%d", SendgridEmailCol), CSVOptions{
+	FieldsPerRecord: -1,
+	Description:     "SBL: imported from SendGrid",
+})
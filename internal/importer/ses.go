@@ -0,0 +1,31 @@
+package importer
+
+import "strings"
+
+// SES imports the account-level suppression list of Amazon SES, as printed by
+// `aws sesv2 list-suppressed-destinations`. The pages of a paginated listing
+// may be concatenated in one file or imported as several files.
+var SES = ItemSource("ses", ItemOptions{
+	Keys:  []string{"SuppressedDestinationSummaries"},
+	Entry: sesEntry,
+})
+
+func sesEntry(item Item, row *Row) {
+	row.Entry.Recipient = item.String("EmailAddress")
+
+	switch strings.ToUpper(item.String("Reason")) {
+	case "BOUNCE":
+		row.Reason = ReasonHardBounce
+		row.Entry.Description = "SESB: bounce"
+	case "COMPLAINT":
+		row.Reason = ReasonSpamComplaint
+		row.Entry.Description = "SESC: spam complaint"
+	default:
+		row.Skip = SkipNotSuppressed
+		return
+	}
+
+	if updated := item.String("LastUpdateTime"); updated != "" {
+		row.Entry.Description += " on " + updated
+	}
+}
@@ -0,0 +1,53 @@
+package importer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var sesPage1 = []wantRow{
+	{"bounced@example.com", ReasonHardBounce, "SESB: bounce on 2016-05-02T14:21:37.125000+00:00", ""},
+	{"complained@example.com", ReasonSpamComplaint, "SESC: spam complaint on 2016-05-03T09:02:11.480000+00:00", ""},
+	{"not-an-address", "", "", SkipInvalid},
+}
+
+var sesPage2 = []wantRow{
+	{"Bounced@example.com", ReasonSpamComplaint, "SESC: spam complaint on 2016-05-04T08:12:54.003000+00:00", ""},
+	{"unknown-reason@example.com", "", "", SkipNotSuppressed},
+	{"last@example.org", ReasonHardBounce, "SESB: bounce on 2016-05-06T22:01:43.910000+00:00", ""},
+}
+
+func TestSESPages(t *testing.T) {
+	checkRows(t, readRows(t, SES, openTestdata(t, "ses-page-1.json"), Position{}), sesPage1)
+	checkRows(t, readRows(t, SES, openTestdata(t, "ses-page-2.json"), Position{}), sesPage2)
+}
+
+// TestSESConcatenatedPages reads the pages of a listing printed one after the
+// other in the same input, from the start and resuming after the fourth item.
+func TestSESConcatenatedPages(t *testing.T) {
+	var listing []byte
+	for _, name := range []string{"ses-page-1.json", "ses-page-2.json"} {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		listing = append(listing, data...)
+	}
+
+	all := append(append([]wantRow{}, sesPage1...), sesPage2...)
+	checkRows(t, readRows(t, SES, bytes.NewReader(listing), Position{}), all)
+
+	rows := readRows(t, SES, bytes.NewReader(listing), Position{Rows: 4})
+	checkRows(t, rows, all[4:])
+	if len(rows) > 0 && rows[0].Number != 5 {
+		t.Errorf("resumed at row %d, want 5", rows[0].Number)
+	}
+}
+
+func TestSESRejectsMapping(t *testing.T) {
+	if _, err := SES.New(openTestdata(t, "ses-page-1.json"), Position{}, Mapping{FieldEmail: {Index: 0}}); err == nil {
+		t.Error("SES accepted a column mapping")
+	}
+}
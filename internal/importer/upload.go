@@ -0,0 +1,157 @@
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	sp "github.com/SparkPost/gosparkpost"
+)
+
+// Batch is a group of entries uploaded with a single request, along with the
+// position in the input just after its last row.
+type Batch struct {
+	Number  int
+	Entries []sp.WritableSuppressionEntry
+	Rows    int64
+	Offset  int64
+}
+
+// BatchError reports the failure of a batch.
+type BatchError struct {
+	Number int
+	Err    error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch %d: %s", e.Number, e.Err)
+}
+
+// Uploader uploads batches with up to a fixed number of concurrent requests.
+// Batches complete in any order but are reported to done in batch order, so
+// done always sees the highest batch below which every batch was uploaded.
+// After the first failure, or Stop, no more batches are accepted while the
+// ones in flight are allowed to finish.
+type Uploader struct {
+	upload func(*Batch) error
+	done   func(*Batch)
+
+	jobs    chan *Batch
+	results chan result
+	stop    chan struct{}
+	once    sync.Once
+	workers sync.WaitGroup
+	collect sync.WaitGroup
+
+	next    int
+	pending map[int]*Batch
+	errs    []error
+}
+
+type result struct {
+	batch *Batch
+	err   error
+}
+
+// NewUploader starts concurrency workers calling upload for every submitted
+// batch. Batch numbers must be consecutive starting at first; done is called
+// from a single goroutine.
+func NewUploader(concurrency, first int, upload func(*Batch) error, done func(*Batch)) *Uploader {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	u := &Uploader{
+		upload:  upload,
+		done:    done,
+		jobs:    make(chan *Batch),
+		results: make(chan result, concurrency),
+		stop:    make(chan struct{}),
+		next:    first,
+		pending: map[int]*Batch{},
+	}
+
+	u.workers.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go u.work()
+	}
+	u.collect.Add(1)
+	go u.collectResults()
+
+	return u
+}
+
+// Submit hands b to the next idle worker, waiting for one if needed.
+// It returns false, without uploading b, once the Uploader was stopped.
+func (u *Uploader) Submit(b *Batch) bool {
+	select {
+	case <-u.stop:
+		return false
+	default:
+	}
+
+	select {
+	case <-u.stop:
+		return false
+	case u.jobs <- b:
+		return true
+	}
+}
+
+// Stop prevents any further batch from being submitted.
+func (u *Uploader) Stop() {
+	u.once.Do(func() { close(u.stop) })
+}
+
+// Stopped reports whether Stop was called or a batch failed.
+func (u *Uploader) Stopped() bool {
+	select {
+	case <-u.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait waits for the batches in flight and returns the errors of the failed
+// ones in batch order. No batch may be submitted afterwards.
+func (u *Uploader) Wait() []error {
+	close(u.jobs)
+	u.workers.Wait()
+	close(u.results)
+	u.collect.Wait()
+
+	sort.Slice(u.errs, func(i, j int) bool {
+		return u.errs[i].(*BatchError).Number < u.errs[j].(*BatchError).Number
+	})
+	return u.errs
+}
+
+func (u *Uploader) work() {
+	defer u.workers.Done()
+	for b := range u.jobs {
+		u.results <- result{batch: b, err: u.upload(b)}
+	}
+}
+
+func (u *Uploader) collectResults() {
+	defer u.collect.Done()
+	for r := range u.results {
+		if r.err != nil {
+			u.errs = append(u.errs, &BatchError{Number: r.batch.Number, Err: r.err})
+			u.Stop()
+			continue
+		}
+
+		u.pending[r.batch.Number] = r.batch
+		for {
+			b, ok := u.pending[u.next]
+			if !ok {
+				break
+			}
+			delete(u.pending, u.next)
+			u.done(b)
+			u.next++
+		}
+	}
+}
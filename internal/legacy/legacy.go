@@ -0,0 +1,128 @@
+// Package legacy keeps the original single purpose binaries working by
+// translating their `--command` style invocations into `sparkpost` subcommands.
+package legacy
+
+import (
+	"os"
+	"strings"
+
+	"github.com/SparkPost/sparkpost-cli/internal/commands"
+	"github.com/codegangsta/cli"
+)
+
+// Run executes the `sparkpost` command group for an old style invocation.
+// The subcommand is taken from any of commandFlags (e.g. "command", "c"),
+// falling back to defaultCommand when none is given.
+func Run(group, defaultCommand string, commandFlags ...string) {
+	app := commands.NewApp()
+	app.Run(translate(app, os.Args, group, defaultCommand, commandFlags))
+}
+
+// translate rewrites `prog [flags...]` into `prog [global flags...] group command [command flags...]`.
+func translate(app *cli.App, args []string, group, defaultCommand string, commandFlags []string) []string {
+	command := defaultCommand
+	var global, rest []string
+
+	isCommandFlag := func(name string) bool {
+		for _, f := range commandFlags {
+			if f == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	globalFlags := flagKinds(append(app.Flags, cli.HelpFlag, cli.VersionFlag))
+
+	// First pass: find the requested command so its flags can be classified.
+	for i := 1; i < len(args); i++ {
+		name, value, inline := splitFlag(args[i])
+		if name == "" || !isCommandFlag(name) {
+			continue
+		}
+		if inline {
+			command = value
+		} else if i+1 < len(args) {
+			command = args[i+1]
+		}
+		break
+	}
+
+	commandBoolFlags := map[string]bool{}
+	if cmd := app.Command(group); cmd != nil {
+		for _, sub := range cmd.Subcommands {
+			if sub.HasName(command) {
+				commandBoolFlags = flagKinds(sub.Flags)
+			}
+		}
+	}
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		name, _, inline := splitFlag(arg)
+		if name == "" {
+			rest = append(rest, arg)
+			continue
+		}
+
+		takesValue := !inline
+		if isCommandFlag(name) {
+			if takesValue {
+				i++
+			}
+			continue
+		}
+
+		target := &rest
+		if isBool, ok := globalFlags[name]; ok {
+			target = &global
+			takesValue = takesValue && !isBool
+		} else if commandBoolFlags[name] {
+			takesValue = false
+		}
+
+		*target = append(*target, arg)
+		if takesValue && i+1 < len(args) {
+			i++
+			*target = append(*target, args[i])
+		}
+	}
+
+	translated := []string{args[0]}
+	translated = append(translated, global...)
+	translated = append(translated, group, command)
+	return append(translated, rest...)
+}
+
+// splitFlag returns the name of the flag in arg and any inline `=value`.
+func splitFlag(arg string) (name, value string, inline bool) {
+	if len(arg) < 2 || arg[0] != '-' {
+		return "", "", false
+	}
+	name = strings.TrimLeft(arg, "-")
+	if i := strings.Index(name, "="); i >= 0 {
+		return name[:i], name[i+1:], true
+	}
+	return name, "", false
+}
+
+// flagKinds maps every name and alias in flags to whether it is a boolean flag.
+func flagKinds(flags []cli.Flag) map[string]bool {
+	kinds := map[string]bool{}
+	for _, f := range flags {
+		isBool := false
+		switch f.(type) {
+		case cli.BoolFlag, cli.BoolTFlag:
+			isBool = true
+		}
+		for _, name := range strings.Split(f.GetName(), ",") {
+			kinds[strings.TrimSpace(name)] = isBool
+		}
+	}
+	return kinds
+}
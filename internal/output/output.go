@@ -0,0 +1,368 @@
+// Package output writes command results as JSON, NDJSON, CSV, TSV, aligned
+// tables or YAML, so every command can be piped into other tools.
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Supported output formats.
+const (
+	JSON   = "json"
+	NDJSON = "ndjson"
+	CSV    = "csv"
+	TSV    = "tsv"
+	Table  = "table"
+	YAML   = "yaml"
+)
+
+// Formats lists every format accepted by New.
+var Formats = []string{JSON, NDJSON, CSV, TSV, Table, YAML}
+
+// Writer writes records sharing the same columns.
+type Writer interface {
+	// Write writes one record. Values are given in column order.
+	Write(values ...interface{}) error
+	// Close flushes any buffered output and terminates the document.
+	Close() error
+}
+
+// Validate returns an error if format is not one of Formats.
+func Validate(format string) error {
+	for _, f := range Formats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format '%s', expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+// New returns a Writer producing format on w for records with the given columns.
+func New(w io.Writer, format string, columns []string) (Writer, error) {
+	switch format {
+	case JSON:
+		return &jsonWriter{w: w, columns: columns}, nil
+	case NDJSON:
+		return &ndjsonWriter{w: w, columns: columns}, nil
+	case CSV, TSV:
+		cw := csv.NewWriter(w)
+		if format == TSV {
+			cw.Comma = '\t'
+		}
+		return &csvWriter{w: cw, columns: columns}, nil
+	case Table:
+		return &tableWriter{w: tabwriter.NewWriter(w, 0, 4, 2, ' ', 0), columns: columns}, nil
+	case YAML:
+		return &yamlWriter{w: w, columns: columns}, nil
+	}
+	return nil, Validate(format)
+}
+
+// Text returns the representation of v used by the text based formats.
+func Text(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	case json.RawMessage:
+		return string(v)
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// object encodes a record as a JSON object keeping the column order.
+func object(columns []string, values []interface{}) ([]byte, error) {
+	if len(values) != len(columns) {
+		return nil, fmt.Errorf("output: got %d values for %d columns", len(values), len(columns))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, column := range columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(column)
+		buf.Write(key)
+		buf.WriteByte(':')
+
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// textRecord converts values to their text representation.
+func textRecord(columns []string, values []interface{}) ([]string, error) {
+	if len(values) != len(columns) {
+		return nil, fmt.Errorf("output: got %d values for %d columns", len(values), len(columns))
+	}
+
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = Text(v)
+	}
+	return record, nil
+}
+
+// jsonWriter streams records as a JSON array with one object per line.
+type jsonWriter struct {
+	w       io.Writer
+	columns []string
+	count   int
+}
+
+func (j *jsonWriter) Write(values ...interface{}) error {
+	obj, err := object(j.columns, values)
+	if err != nil {
+		return err
+	}
+
+	prefix := ",\n"
+	if j.count == 0 {
+		prefix = "[\n"
+	}
+	j.count++
+
+	_, err = fmt.Fprintf(j.w, "%s%s", prefix, obj)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.count == 0 {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
+
+// ndjsonWriter writes one JSON object per line.
+type ndjsonWriter struct {
+	w       io.Writer
+	columns []string
+}
+
+func (n *ndjsonWriter) Write(values ...interface{}) error {
+	obj, err := object(n.columns, values)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(n.w, "%s\n", obj)
+	return err
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+// csvWriter writes RFC 4180 records preceded by a header row.
+type csvWriter struct {
+	w       *csv.Writer
+	columns []string
+	started bool
+}
+
+func (c *csvWriter) Write(values ...interface{}) error {
+	record, err := textRecord(c.columns, values)
+	if err != nil {
+		return err
+	}
+	if !c.started {
+		c.started = true
+		if err := c.w.Write(c.columns); err != nil {
+			return err
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	if !c.started {
+		c.started = true
+		c.w.Write(c.columns)
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// tableWriter aligns records in columns for reading on a terminal.
+type tableWriter struct {
+	w       *tabwriter.Writer
+	columns []string
+	started bool
+}
+
+// tableCell keeps a value on a single line and out of the column separators.
+func tableCell(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", "").Replace(s)
+}
+
+func (t *tableWriter) writeRow(cells []string) error {
+	for i := range cells {
+		cells[i] = tableCell(cells[i])
+	}
+	_, err := fmt.Fprintln(t.w, strings.Join(cells, "\t"))
+	return err
+}
+
+func (t *tableWriter) Write(values ...interface{}) error {
+	record, err := textRecord(t.columns, values)
+	if err != nil {
+		return err
+	}
+	if !t.started {
+		t.started = true
+		header := make([]string, len(t.columns))
+		for i, column := range t.columns {
+			header[i] = strings.ToUpper(column)
+		}
+		if err := t.writeRow(header); err != nil {
+			return err
+		}
+	}
+	return t.writeRow(record)
+}
+
+func (t *tableWriter) Close() error {
+	return t.w.Flush()
+}
+
+// yamlWriter writes records as a YAML sequence of mappings.
+type yamlWriter struct {
+	w       io.Writer
+	columns []string
+	count   int
+}
+
+func (y *yamlWriter) Write(values ...interface{}) error {
+	if len(values) != len(y.columns) {
+		return fmt.Errorf("output: got %d values for %d columns", len(values), len(y.columns))
+	}
+
+	item := make(yaml.MapSlice, len(values))
+	for i, v := range values {
+		if raw, ok := v.(json.RawMessage); ok {
+			var decoded interface{}
+			if err := json.Unmarshal(raw, &decoded); err == nil {
+				v = decoded
+			}
+		}
+		item[i] = yaml.MapItem{Key: y.columns[i], Value: v}
+	}
+	y.count++
+
+	b, err := yaml.Marshal([]yaml.MapSlice{item})
+	if err != nil {
+		return err
+	}
+	_, err = y.w.Write(b)
+	return err
+}
+
+func (y *yamlWriter) Close() error {
+	if y.count == 0 {
+		_, err := io.WriteString(y.w, "[]\n")
+		return err
+	}
+	return nil
+}
+
+// DocumentWriter writes whole JSON documents, preserving every field they hold.
+type DocumentWriter interface {
+	// Write writes one JSON document.
+	Write(doc json.RawMessage) error
+	// Close flushes any buffered output and terminates the document stream.
+	Close() error
+}
+
+// IsDocumentFormat reports whether format can hold arbitrary documents, see NewDocumentWriter.
+func IsDocumentFormat(format string) bool {
+	return format == JSON || format == NDJSON || format == YAML
+}
+
+// NewDocumentWriter returns a DocumentWriter producing format on w.
+// Only JSON, NDJSON and YAML can represent arbitrary documents.
+func NewDocumentWriter(w io.Writer, format string) (DocumentWriter, error) {
+	if !IsDocumentFormat(format) {
+		if err := Validate(format); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("output format '%s' cannot hold whole documents, use json, ndjson or yaml", format)
+	}
+	return &documentWriter{w: w, format: format}, nil
+}
+
+type documentWriter struct {
+	w      io.Writer
+	format string
+	count  int
+}
+
+func (d *documentWriter) Write(doc json.RawMessage) error {
+	var err error
+	switch d.format {
+	case YAML:
+		var decoded interface{}
+		if err = json.Unmarshal(doc, &decoded); err != nil {
+			return err
+		}
+		var b []byte
+		if b, err = yaml.Marshal([]interface{}{decoded}); err == nil {
+			_, err = d.w.Write(b)
+		}
+	default:
+		var buf bytes.Buffer
+		if err = json.Compact(&buf, doc); err != nil {
+			return err
+		}
+		prefix := ""
+		if d.format == JSON {
+			prefix = ",\n"
+			if d.count == 0 {
+				prefix = "[\n"
+			}
+		} else {
+			buf.WriteByte('\n')
+		}
+		_, err = fmt.Fprintf(d.w, "%s%s", prefix, buf.Bytes())
+	}
+	d.count++
+	return err
+}
+
+func (d *documentWriter) Close() error {
+	if d.format == NDJSON {
+		return nil
+	}
+	if d.count == 0 {
+		_, err := io.WriteString(d.w, "[]\n")
+		return err
+	}
+	if d.format == JSON {
+		_, err := io.WriteString(d.w, "\n]\n")
+		return err
+	}
+	return nil
+}
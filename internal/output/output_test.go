@@ -0,0 +1,194 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+var columns = []string{"recipient", "type", "description"}
+
+// render writes records with a Writer for format and returns its output.
+func render(t *testing.T, format string, records ...[]interface{}) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := New(&buf, format, columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, values := range records {
+		if err := w.Write(values...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestWriter(t *testing.T) {
+	records := [][]interface{}{
+		{"name@example.com", "transactional", `bounced, "mailbox full"`},
+		{"other@example.com", nil, "line\nbreak\tand tab"},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{CSV, "recipient,type,description\n" +
+			"name@example.com,transactional,\"bounced, \"\"mailbox full\"\"\"\n" +
+			"other@example.com,,\"line\nbreak\tand tab\"\n"},
+		{TSV, "recipient\ttype\tdescription\n" +
+			"name@example.com\ttransactional\t\"bounced, \"\"mailbox full\"\"\"\n" +
+			"other@example.com\t\t\"line\nbreak\tand tab\"\n"},
+		{JSON, "[\n" +
+			`{"recipient":"name@example.com","type":"transactional","description":"bounced, \"mailbox full\""},` + "\n" +
+			`{"recipient":"other@example.com","type":null,"description":"line\nbreak\tand tab"}` + "\n]\n"},
+		{NDJSON, `{"recipient":"name@example.com","type":"transactional","description":"bounced, \"mailbox full\""}` + "\n" +
+			`{"recipient":"other@example.com","type":null,"description":"line\nbreak\tand tab"}` + "\n"},
+		{Table, "RECIPIENT          TYPE           DESCRIPTION\n" +
+			"name@example.com   transactional  bounced, \"mailbox full\"\n" +
+			"other@example.com                 line break and tab\n"},
+		{YAML, "- recipient: name@example.com\n  type: transactional\n  description: bounced, \"mailbox full\"\n" +
+			"- recipient: other@example.com\n  type: null\n  description: \"line\\nbreak\\tand tab\"\n"},
+	}
+
+	for _, test := range tests {
+		if got := render(t, test.format, records...); got != test.want {
+			t.Errorf("%s output:\n%s\nwant:\n%s", test.format, got, test.want)
+		}
+	}
+}
+
+func TestWriterEmpty(t *testing.T) {
+	tests := map[string]string{
+		CSV:    "recipient,type,description\n",
+		TSV:    "recipient\ttype\tdescription\n",
+		JSON:   "[]\n",
+		NDJSON: "",
+		Table:  "",
+		YAML:   "[]\n",
+	}
+	for format, want := range tests {
+		if got := render(t, format); got != want {
+			t.Errorf("%s output without records = %q, want %q", format, got, want)
+		}
+	}
+}
+
+func TestWriterColumnCount(t *testing.T) {
+	for _, format := range Formats {
+		w, err := New(&bytes.Buffer{}, format, columns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Write("name@example.com"); err == nil {
+			t.Errorf("%s writer accepted 1 value for %d columns", format, len(columns))
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, format := range Formats {
+		if err := Validate(format); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := Validate("xml"); err == nil {
+		t.Error("Validate accepted xml")
+	}
+	if _, err := New(&bytes.Buffer{}, "xml", columns); err == nil {
+		t.Error("New accepted xml")
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, ""},
+		{"text", "text"},
+		{[]string{"a", "b"}, "a,b"},
+		{json.RawMessage(`{"a":1}`), `{"a":1}`},
+		{map[string]interface{}{"a": 1.5}, `{"a":1.5}`},
+		{[]interface{}{"a", 2.0}, `["a",2]`},
+		{42, "42"},
+		{true, "true"},
+	}
+	for _, test := range tests {
+		if got := Text(test.value); got != test.want {
+			t.Errorf("Text(%#v) = %q, want %q", test.value, got, test.want)
+		}
+	}
+}
+
+func TestDocumentWriter(t *testing.T) {
+	docs := []json.RawMessage{
+		json.RawMessage(`{ "id": "1", "rcpt_to": "name@example.com", "tags": ["a", "b"] }`),
+		json.RawMessage(`{"id":"2","geo":{"country":"FR"}}`),
+	}
+
+	tests := []struct {
+		format string
+		want   string
+		empty  string
+	}{
+		{JSON, "[\n" + `{"id":"1","rcpt_to":"name@example.com","tags":["a","b"]},` + "\n" + `{"id":"2","geo":{"country":"FR"}}` + "\n]\n", "[]\n"},
+		{NDJSON, `{"id":"1","rcpt_to":"name@example.com","tags":["a","b"]}` + "\n" + `{"id":"2","geo":{"country":"FR"}}` + "\n", ""},
+		{YAML, "- id: \"1\"\n  rcpt_to: name@example.com\n  tags:\n  - a\n  - b\n- geo:\n    country: FR\n  id: \"2\"\n", "[]\n"},
+	}
+
+	for _, test := range tests {
+		for _, written := range [][]json.RawMessage{docs, nil} {
+			var buf bytes.Buffer
+			w, err := NewDocumentWriter(&buf, test.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, doc := range written {
+				if err := w.Write(doc); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			want := test.want
+			if written == nil {
+				want = test.empty
+			}
+			if buf.String() != want {
+				t.Errorf("%s documents:\n%s\nwant:\n%s", test.format, buf.String(), want)
+			}
+		}
+	}
+
+	for _, format := range []string{CSV, TSV, Table} {
+		if _, err := NewDocumentWriter(&bytes.Buffer{}, format); err == nil {
+			t.Errorf("NewDocumentWriter accepted %s", format)
+		}
+	}
+}
+
+func TestYAMLRawMessage(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := New(&buf, YAML, []string{"id", "event"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write("1", json.RawMessage(`{"type":"bounce","bounce_class":"10"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "- id: \"1\"\n  event:\n    bounce_class: \"10\"\n    type: bounce\n"
+	if buf.String() != want {
+		t.Errorf("YAML output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,112 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limiter is an http.RoundTripper that spreads requests out to at most RPS
+// requests per second using a token bucket holding up to Burst tokens. It also
+// counts the requests made so the effective throughput can be reported. A wait
+// ends early with the error of the request context once it is done.
+type Limiter struct {
+	// Next performs the requests, http.DefaultTransport when nil.
+	Next http.RoundTripper
+	// RPS is the sustained number of requests per second, unlimited when zero or less.
+	RPS float64
+	// Burst is the number of requests that may be made at once after an idle
+	// period, 1 when zero or less.
+	Burst int
+
+	// sleep and now are replaced in tests.
+	sleep func(time.Duration)
+	now   func() time.Time
+
+	mu       sync.Mutex
+	tokens   float64
+	last     time.Time
+	started  time.Time
+	requests int
+}
+
+// RoundTrip implements http.RoundTripper.
+func (l *Limiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if wait := l.reserve(); wait > 0 {
+		if err := l.doSleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+
+	next := l.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return next.RoundTrip(req)
+}
+
+// reserve takes a token from the bucket and returns how long the caller must
+// wait before the token is actually available.
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock()
+	if l.requests == 0 {
+		l.started = now
+	}
+	l.requests++
+
+	if l.RPS <= 0 {
+		return 0
+	}
+
+	burst := float64(l.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	if l.last.IsZero() {
+		l.tokens = burst
+	} else {
+		l.tokens += now.Sub(l.last).Seconds() * l.RPS
+		if l.tokens > burst {
+			l.tokens = burst
+		}
+	}
+	l.last = now
+
+	// Tokens may go negative: the debt is what later callers wait for.
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.RPS * float64(time.Second))
+}
+
+// Throughput returns the number of requests made so far and the time elapsed
+// since the first one.
+func (l *Limiter) Throughput() (int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.requests == 0 {
+		return 0, 0
+	}
+	return l.requests, l.clock().Sub(l.started)
+}
+
+func (l *Limiter) clock() time.Time {
+	if l.now != nil {
+		return l.now()
+	}
+	return time.Now()
+}
+
+func (l *Limiter) doSleep(ctx context.Context, d time.Duration) error {
+	if l.sleep != nil {
+		l.sleep(d)
+		return ctx.Err()
+	}
+	return wait(ctx, d)
+}
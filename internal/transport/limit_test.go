@@ -0,0 +1,98 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	tests := []struct {
+		name  string
+		rps   float64
+		burst int
+		want  []time.Duration
+	}{
+		{"unlimited", 0, 0, nil},
+		{"2 per second", 2, 0, []time.Duration{500 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond}},
+		{"burst", 4, 3, []time.Duration{250 * time.Millisecond, 250 * time.Millisecond}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newThrottlingServer(status(http.StatusOK))
+			defer server.Close()
+			clock := newFakeClock()
+			limiter := &Limiter{RPS: test.rps, Burst: test.burst, sleep: clock.sleep, now: clock.now}
+
+			for i := 0; i < 5; i++ {
+				get(t, limiter, server.URL)
+			}
+
+			if len(clock.sleeps) != len(test.want) {
+				t.Fatalf("waits = %v, want %v", clock.sleeps, test.want)
+			}
+			for i, want := range test.want {
+				if clock.sleeps[i] != want {
+					t.Errorf("waits = %v, want %v", clock.sleeps, test.want)
+					break
+				}
+			}
+
+			requests, elapsed := limiter.Throughput()
+			var slept time.Duration
+			for _, d := range clock.sleeps {
+				slept += d
+			}
+			if requests != 5 || elapsed != slept {
+				t.Errorf("Throughput() = %d, %s, want 5, %s", requests, elapsed, slept)
+			}
+		})
+	}
+}
+
+func TestLimiterCountsRetries(t *testing.T) {
+	server := newThrottlingServer(status(http.StatusTooManyRequests, "Retry-After", "0"), status(http.StatusOK))
+	defer server.Close()
+	clock := newFakeClock()
+	limiter := &Limiter{RPS: 1, sleep: clock.sleep, now: clock.now}
+	retry := newRetry(clock, 5)
+	retry.Next = limiter
+
+	get(t, retry, server.URL)
+
+	if requests, _ := limiter.Throughput(); requests != 2 {
+		t.Errorf("limiter counted %d requests, want 2", requests)
+	}
+	// The retry is held back by the limiter after the immediate Retry-After.
+	if len(clock.sleeps) != 2 || clock.sleeps[0] != 0 || clock.sleeps[1] != time.Second {
+		t.Errorf("waits = %v, want [0s 1s]", clock.sleeps)
+	}
+}
+
+// TestLimiterCancelled waits for real: the request context must cut the wait
+// for a token short.
+func TestLimiterCancelled(t *testing.T) {
+	server := newThrottlingServer(status(http.StatusOK))
+	defer server.Close()
+	limiter := &Limiter{RPS: 0.01}
+	get(t, limiter, server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := time.Now()
+	if _, err := limiter.RoundTrip(req.WithContext(ctx)); err != context.DeadlineExceeded {
+		t.Errorf("RoundTrip() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("RoundTrip() returned after %s", elapsed)
+	}
+	if requests := server.requests(); requests != 1 {
+		t.Errorf("server received %d requests, want 1", requests)
+	}
+}
@@ -0,0 +1,252 @@
+// Package transport provides the http.RoundTripper middlewares shared by
+// every command: retrying throttled requests and limiting the request rate.
+package transport
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults used by Retry when the corresponding field is zero.
+const (
+	DefaultMaxAttempts = 5
+	DefaultBaseDelay   = 500 * time.Millisecond
+	DefaultMaxDelay    = 60 * time.Second
+)
+
+// Retry is an http.RoundTripper that retries requests throttled by SparkPost
+// (429) or failed by the server (5xx) or the network, waiting between attempts
+// with jittered exponential backoff. A Retry-After header, or exhausted
+// X-RateLimit-Remaining with X-RateLimit-Reset, overrides the backoff. A wait
+// ends early with the error of the request context once it is done.
+type Retry struct {
+	// Next performs the requests, http.DefaultTransport when nil.
+	Next http.RoundTripper
+	// MaxAttempts is the total number of attempts per request, including the first.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry; it doubles on every attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff.
+	MaxDelay time.Duration
+	// Logf, when set, is called for every retry.
+	Logf func(format string, v ...interface{})
+
+	// sleep and now are replaced in tests.
+	sleep func(time.Duration)
+	now   func() time.Time
+
+	mu sync.Mutex
+	// notBefore holds requests back until the rate limit window reported by the server resets.
+	notBefore time.Time
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Retry) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	maxAttempts := r.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := r.waitForWindow(req); err != nil {
+			return nil, err
+		}
+
+		attemptReq := req.Clone(req.Context())
+		if req.Body != nil {
+			attemptReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+			attemptReq.GetBody = func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(bytes.NewReader(body)), nil
+			}
+		}
+
+		res, err := r.next().RoundTrip(attemptReq)
+		if res != nil {
+			r.recordWindow(res)
+		}
+
+		if !retryable(res, err) || attempt >= maxAttempts || req.Context().Err() != nil {
+			return res, err
+		}
+
+		delay := r.backoff(attempt)
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = res.Status
+			if wait, ok := r.serverDelay(res); ok {
+				delay = wait
+			}
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		if r.Logf != nil {
+			r.Logf("Retry %d/%d of %s %s in %s: %s", attempt, maxAttempts-1, req.Method, req.URL.Path, delay, reason)
+		}
+		if err := r.doSleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable reports whether the outcome of an attempt is worth retrying.
+func retryable(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+}
+
+func (r *Retry) next() http.RoundTripper {
+	if r.Next != nil {
+		return r.Next
+	}
+	return http.DefaultTransport
+}
+
+// backoff returns the jittered wait before retry number attempt, which is between
+// half and all of BaseDelay * 2^(attempt-1), capped at MaxDelay.
+func (r *Retry) backoff(attempt int) time.Duration {
+	base, max := r.BaseDelay, r.MaxDelay
+	if base <= 0 {
+		base = DefaultBaseDelay
+	}
+	if max <= 0 {
+		max = DefaultMaxDelay
+	}
+
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// serverDelay returns the wait requested by the server through Retry-After
+// (seconds or an HTTP date) or an exhausted X-RateLimit window.
+func (r *Retry) serverDelay(res *http.Response) (time.Duration, bool) {
+	if value := res.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return clampWait(at.Sub(r.clock())), true
+		}
+	}
+
+	if reset, ok := r.rateLimitReset(res); ok {
+		return clampWait(reset.Sub(r.clock())), true
+	}
+	return 0, false
+}
+
+// rateLimitReset returns when the rate limit window resets if res reports it as exhausted.
+// X-RateLimit-Reset is accepted either as a Unix timestamp or as seconds from now.
+func (r *Retry) rateLimitReset(res *http.Response) (time.Time, bool) {
+	if res.Header.Get("X-RateLimit-Remaining") != "0" {
+		return time.Time{}, false
+	}
+
+	reset, err := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil || reset < 0 {
+		return time.Time{}, false
+	}
+	if reset > 1000000000 {
+		return time.Unix(reset, 0), true
+	}
+	return r.clock().Add(time.Duration(reset) * time.Second), true
+}
+
+// recordWindow remembers an exhausted rate limit window so the next request waits for it.
+func (r *Retry) recordWindow(res *http.Response) {
+	if res.StatusCode == http.StatusTooManyRequests {
+		// The retry loop already waits for this one.
+		return
+	}
+	if reset, ok := r.rateLimitReset(res); ok {
+		r.mu.Lock()
+		if reset.After(r.notBefore) {
+			r.notBefore = reset
+		}
+		r.mu.Unlock()
+	}
+}
+
+// waitForWindow sleeps until a previously exhausted rate limit window has
+// reset, or the request is cancelled.
+func (r *Retry) waitForWindow(req *http.Request) error {
+	r.mu.Lock()
+	wait := r.notBefore.Sub(r.clock())
+	r.mu.Unlock()
+
+	if wait > 0 {
+		if r.Logf != nil {
+			r.Logf("Rate limit exhausted, waiting %s before %s %s", wait, req.Method, req.URL.Path)
+		}
+		return r.doSleep(req.Context(), wait)
+	}
+	return nil
+}
+
+func (r *Retry) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+func (r *Retry) doSleep(ctx context.Context, d time.Duration) error {
+	if r.sleep != nil {
+		r.sleep(d)
+		return ctx.Err()
+	}
+	return wait(ctx, d)
+}
+
+// wait pauses for d, or returns the error of ctx as soon as it is done.
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// clampWait keeps a server provided wait within sane bounds.
+func clampWait(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	if d > 15*time.Minute {
+		return 15 * time.Minute
+	}
+	return d
+}
@@ -0,0 +1,269 @@
+package transport
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock stands in for time.Now and time.Sleep, recording the waits.
+type fakeClock struct {
+	mu     sync.Mutex
+	t      time.Time
+	sleeps []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2016, 4, 11, 20, 15, 55, 0, time.UTC)}
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sleeps = append(c.sleeps, d)
+	c.t = c.t.Add(d)
+}
+
+// throttlingServer answers each request with the next of responses, the last
+// one once they are exhausted, and records the bodies it received.
+type throttlingServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	responses []func(w http.ResponseWriter)
+	bodies    []string
+}
+
+func newThrottlingServer(responses ...func(w http.ResponseWriter)) *throttlingServer {
+	s := &throttlingServer{responses: responses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		s.mu.Lock()
+		i := len(s.bodies)
+		s.bodies = append(s.bodies, string(body))
+		s.mu.Unlock()
+
+		if i >= len(s.responses) {
+			i = len(s.responses) - 1
+		}
+		s.responses[i](w)
+	}))
+	return s
+}
+
+func (s *throttlingServer) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.bodies)
+}
+
+func status(code int, header ...string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for i := 0; i+1 < len(header); i += 2 {
+			w.Header().Set(header[i], header[i+1])
+		}
+		w.WriteHeader(code)
+	}
+}
+
+func newRetry(clock *fakeClock, maxAttempts int) *Retry {
+	return &Retry{
+		MaxAttempts: maxAttempts,
+		BaseDelay:   time.Second,
+		MaxDelay:    10 * time.Second,
+		sleep:       clock.sleep,
+		now:         clock.now,
+	}
+}
+
+func get(t *testing.T, rt http.RoundTripper, url string) *http.Response {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	return res
+}
+
+func TestRetryThrottledThenOK(t *testing.T) {
+	server := newThrottlingServer(status(http.StatusTooManyRequests), status(http.StatusOK))
+	defer server.Close()
+	clock := newFakeClock()
+
+	res := get(t, newRetry(clock, 5), server.URL)
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", res.StatusCode)
+	}
+	if n := server.requests(); n != 2 {
+		t.Errorf("server received %d requests, want 2", n)
+	}
+	if len(clock.sleeps) != 1 || clock.sleeps[0] < 500*time.Millisecond || clock.sleeps[0] > time.Second {
+		t.Errorf("waits = %v, want one backoff between 500ms and 1s", clock.sleeps)
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	server := newThrottlingServer(status(http.StatusServiceUnavailable))
+	defer server.Close()
+	clock := newFakeClock()
+
+	res := get(t, newRetry(clock, 3), server.URL)
+
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", res.StatusCode)
+	}
+	if n := server.requests(); n != 3 {
+		t.Errorf("server received %d requests, want 3", n)
+	}
+	if len(clock.sleeps) != 2 {
+		t.Fatalf("waits = %v, want 2", clock.sleeps)
+	}
+	if clock.sleeps[1] < time.Second || clock.sleeps[1] > 2*time.Second {
+		t.Errorf("second backoff = %s, want between 1s and 2s", clock.sleeps[1])
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	clock := newFakeClock()
+	date := clock.now().Add(30 * time.Second).Format(http.TimeFormat)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"seconds", "7", 7 * time.Second},
+		{"HTTP date", date, 30 * time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newThrottlingServer(status(http.StatusTooManyRequests, "Retry-After", test.value), status(http.StatusOK))
+			defer server.Close()
+			clock := newFakeClock()
+
+			res := get(t, newRetry(clock, 5), server.URL)
+
+			if res.StatusCode != http.StatusOK {
+				t.Errorf("status = %d, want 200", res.StatusCode)
+			}
+			if len(clock.sleeps) != 1 || clock.sleeps[0] != test.want {
+				t.Errorf("waits = %v, want [%s]", clock.sleeps, test.want)
+			}
+		})
+	}
+}
+
+func TestRetryRateLimitWindow(t *testing.T) {
+	clock := newFakeClock()
+	reset := strconv.FormatInt(clock.now().Add(20*time.Second).Unix(), 10)
+
+	tests := []struct {
+		name  string
+		reset string
+	}{
+		{"Unix timestamp", reset},
+		{"seconds", "20"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newThrottlingServer(
+				status(http.StatusOK, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", test.reset),
+				status(http.StatusOK, "X-RateLimit-Remaining", "99"),
+			)
+			defer server.Close()
+			clock := newFakeClock()
+			retry := newRetry(clock, 5)
+
+			get(t, retry, server.URL)
+			if len(clock.sleeps) != 0 {
+				t.Fatalf("first request waited %v", clock.sleeps)
+			}
+
+			get(t, retry, server.URL)
+			if len(clock.sleeps) != 1 || clock.sleeps[0] != 20*time.Second {
+				t.Errorf("waits before the second request = %v, want [20s]", clock.sleeps)
+			}
+
+			get(t, retry, server.URL)
+			if len(clock.sleeps) != 1 {
+				t.Errorf("third request waited, waits = %v", clock.sleeps)
+			}
+		})
+	}
+}
+
+func TestRetryReplaysBody(t *testing.T) {
+	server := newThrottlingServer(status(http.StatusServiceUnavailable), status(http.StatusBadGateway), status(http.StatusOK))
+	defer server.Close()
+	clock := newFakeClock()
+
+	const payload = `{"recipients":[{"recipient":"jane@example.com"}]}`
+	req, err := http.NewRequest("PUT", server.URL, strings.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := newRetry(clock, 5).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", res.StatusCode)
+	}
+	if len(server.bodies) != 3 {
+		t.Fatalf("server received %d requests, want 3", len(server.bodies))
+	}
+	for i, body := range server.bodies {
+		if body != payload {
+			t.Errorf("body of attempt %d = %q, want %q", i+1, body, payload)
+		}
+	}
+}
+
+// TestRetryCancelled waits for real: the request context must cut the
+// backoff short.
+func TestRetryCancelled(t *testing.T) {
+	for _, header := range [][]string{nil, {"Retry-After", "600"}} {
+		server := newThrottlingServer(status(http.StatusServiceUnavailable, header...))
+		retry := &Retry{MaxAttempts: 5, BaseDelay: time.Minute}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		req, err := http.NewRequest("GET", server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		started := time.Now()
+		_, err = retry.RoundTrip(req.WithContext(ctx))
+		cancel()
+		server.Close()
+
+		if err != context.DeadlineExceeded {
+			t.Errorf("RoundTrip() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if elapsed := time.Since(started); elapsed > 5*time.Second {
+			t.Errorf("RoundTrip() returned after %s", elapsed)
+		}
+		if requests := server.requests(); requests != 1 {
+			t.Errorf("server received %d requests, want 1", requests)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package validate
+
+// disposableDomains lists well known disposable address providers. Their
+// subdomains are disposable too.
+var disposableDomains = map[string]bool{
+	"0-mail.com":             true,
+	"10minutemail.com":       true,
+	"10minutemail.net":       true,
+	"20minutemail.com":       true,
+	"33mail.com":             true,
+	"anonbox.net":            true,
+	"armyspy.com":            true,
+	"binkmail.com":           true,
+	"bobmail.info":           true,
+	"cool.fr.nf":             true,
+	"courriel.fr.nf":         true,
+	"cuvox.de":               true,
+	"dayrep.com":             true,
+	"deadaddress.com":        true,
+	"discard.email":          true,
+	"dispostable.com":        true,
+	"dodgit.com":             true,
+	"einrot.com":             true,
+	"emailondeck.com":        true,
+	"fakeinbox.com":          true,
+	"fleckens.hu":            true,
+	"getairmail.com":         true,
+	"getnada.com":            true,
+	"grr.la":                 true,
+	"guerrillamail.biz":      true,
+	"guerrillamail.com":      true,
+	"guerrillamail.de":       true,
+	"guerrillamail.info":     true,
+	"guerrillamail.net":      true,
+	"guerrillamail.org":      true,
+	"guerrillamailblock.com": true,
+	"gustr.com":              true,
+	"harakirimail.com":       true,
+	"incognitomail.com":      true,
+	"jetable.org":            true,
+	"jourrapide.com":         true,
+	"mailcatch.com":          true,
+	"maildrop.cc":            true,
+	"mailexpire.com":         true,
+	"mailforspam.com":        true,
+	"mailinator.com":         true,
+	"mailinator.net":         true,
+	"mailinator2.com":        true,
+	"mailnesia.com":          true,
+	"mailnull.com":           true,
+	"meltmail.com":           true,
+	"mintemail.com":          true,
+	"moncourrier.fr.nf":      true,
+	"monemail.fr.nf":         true,
+	"monmail.fr.nf":          true,
+	"mytemp.email":           true,
+	"mytrashmail.com":        true,
+	"nospam.ze.tc":           true,
+	"nomail.xl.cx":           true,
+	"pokemail.net":           true,
+	"rhyta.com":              true,
+	"sharklasers.com":        true,
+	"spam4.me":               true,
+	"spambox.us":             true,
+	"spamex.com":             true,
+	"spamgourmet.com":        true,
+	"spamhole.com":           true,
+	"speed.1s.fr":            true,
+	"superrito.com":          true,
+	"teleworm.us":            true,
+	"temp-mail.org":          true,
+	"tempail.com":            true,
+	"tempinbox.com":          true,
+	"tempmail.net":           true,
+	"tempmailaddress.com":    true,
+	"tempr.email":            true,
+	"throwawaymail.com":      true,
+	"trash-mail.com":         true,
+	"trashmail.com":          true,
+	"trashmail.net":          true,
+	"trbvm.com":              true,
+	"wegwerfmail.de":         true,
+	"yopmail.com":            true,
+	"yopmail.fr":             true,
+	"yopmail.net":            true,
+}
+
+// roleLocalParts lists the local parts of role addresses, which reach a team
+// or a system rather than a person.
+var roleLocalParts = map[string]bool{
+	"abuse":         true,
+	"admin":         true,
+	"administrator": true,
+	"billing":       true,
+	"compliance":    true,
+	"contact":       true,
+	"devnull":       true,
+	"dns":           true,
+	"ftp":           true,
+	"help":          true,
+	"hostmaster":    true,
+	"info":          true,
+	"inoc":          true,
+	"ispfeedback":   true,
+	"ispsupport":    true,
+	"list":          true,
+	"list-request":  true,
+	"mailer-daemon": true,
+	"marketing":     true,
+	"media":         true,
+	"no-reply":      true,
+	"noc":           true,
+	"noreply":       true,
+	"null":          true,
+	"office":        true,
+	"phish":         true,
+	"phishing":      true,
+	"postmaster":    true,
+	"privacy":       true,
+	"registrar":     true,
+	"root":          true,
+	"sales":         true,
+	"security":      true,
+	"spam":          true,
+	"support":       true,
+	"sysadmin":      true,
+	"tech":          true,
+	"unsubscribe":   true,
+	"usenet":        true,
+	"uucp":          true,
+	"webmaster":     true,
+	"www":           true,
+}
@@ -0,0 +1,254 @@
+// Package validate checks and normalizes the email addresses read by the
+// suppression list imports, telling why an address is rejected.
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Reasons an address is rejected.
+const (
+	ReasonEmpty      = "empty"
+	ReasonSyntax     = "invalid_address"
+	ReasonLocalPart  = "invalid_local_part"
+	ReasonDomain     = "invalid_domain"
+	ReasonTLD        = "missing_tld"
+	ReasonTooLong    = "too_long"
+	ReasonDisposable = "disposable_domain"
+	ReasonRole       = "role_address"
+)
+
+// Limits of RFC 5321 on the length of addresses and their parts.
+const (
+	maxAddress   = 254
+	maxLocalPart = 64
+	maxDomain    = 253
+	maxLabel     = 63
+)
+
+var descriptions = map[string]string{
+	ReasonEmpty:      "It is empty",
+	ReasonSyntax:     "It is not a valid email address",
+	ReasonLocalPart:  "The part before the @ is not valid",
+	ReasonDomain:     "Its domain is not valid",
+	ReasonTLD:        "Its domain has no top-level domain",
+	ReasonTooLong:    "It is too long",
+	ReasonDisposable: "Its domain is a disposable address provider",
+	ReasonRole:       "It is a role address",
+}
+
+// Error is returned for a rejected address.
+type Error struct {
+	Address string
+	// Reason is one of the Reason constants.
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("rejected address '%s': %s", e.Address, e.Reason)
+}
+
+// Describe returns a sentence telling why an address was rejected for reason.
+func Describe(reason string) string {
+	if d, ok := descriptions[reason]; ok {
+		return d
+	}
+	return reason
+}
+
+// IsReason reports whether reason is one of the Reason constants.
+func IsReason(reason string) bool {
+	_, ok := descriptions[reason]
+	return ok
+}
+
+// Normalize parses address as an RFC 5322 addr-spec and returns it in the
+// form stored in the suppression list: whitespace, quotes and brackets around
+// it are removed, and its domain is lowercased and converted to punycode.
+// Addresses given with a display name, such as `Jane <jane@example.com>`, are
+// accepted, and a local part that is not a dot-atom is kept quoted. The error
+// is an *Error telling why address is rejected.
+func Normalize(address string) (string, error) {
+	trimmed := strings.TrimSpace(address)
+	if trimmed == "" {
+		return "", &Error{address, ReasonEmpty}
+	}
+	if len(trimmed) > 7 && strings.EqualFold(trimmed[:7], "mailto:") {
+		trimmed = trimmed[7:]
+	}
+
+	normalized, reason := normalize(trimmed)
+	if reason != "" {
+		// Exports often leave stray quotes or punctuation around the address,
+		// which may parse with them as part of the domain.
+		if stripped := strings.TrimRight(strings.TrimLeft(trimmed, "\"'<>` \t"), "\"'<>` \t.,;:"); stripped != trimmed {
+			switch n, r := normalize(stripped); {
+			case r == "":
+				normalized, reason = n, ""
+			case r != ReasonSyntax:
+				reason = r
+			}
+		}
+	}
+	if reason != "" {
+		return "", &Error{address, reason}
+	}
+	return normalized, nil
+}
+
+// normalize returns the normalized form of address, or the reason it is rejected.
+func normalize(address string) (string, string) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", ReasonSyntax
+	}
+
+	at := strings.LastIndex(parsed.Address, "@")
+	if at < 1 {
+		return "", ReasonSyntax
+	}
+	local, domain := quoteLocal(parsed.Address[:at]), parsed.Address[at+1:]
+
+	if len(local) > maxLocalPart {
+		return "", ReasonLocalPart
+	}
+
+	domain, reason := normalizeDomain(domain)
+	if reason != "" {
+		return "", reason
+	}
+
+	normalized := local + "@" + domain
+	if len(normalized) > maxAddress {
+		return "", ReasonTooLong
+	}
+	return normalized, ""
+}
+
+// quoteLocal returns local, as unquoted by net/mail, quoted again unless it
+// is a dot-atom.
+func quoteLocal(local string) string {
+	if isDotAtom(local) {
+		return local
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(local) + `"`
+}
+
+// isDotAtom reports whether local is a dot-atom of RFC 5322, extended to
+// UTF-8 by RFC 6532.
+func isDotAtom(local string) bool {
+	for _, atom := range strings.Split(local, ".") {
+		if atom == "" {
+			return false
+		}
+		for _, r := range atom {
+			if r < 0x80 && !strings.ContainsRune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!#$%&'*+-/=?^_`{|}~", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// normalizeDomain returns domain in lowercase punycode, or the reason it is rejected.
+func normalizeDomain(domain string) (string, string) {
+	domain = strings.TrimSuffix(domain, ".")
+	if domain == "" || strings.HasPrefix(domain, "[") {
+		// Domain literals such as [192.0.2.1] cannot be suppressed.
+		return "", ReasonDomain
+	}
+
+	ascii, err := idna.Lookup.ToASCII(domain)
+	if err != nil {
+		return "", ReasonDomain
+	}
+	ascii = strings.ToLower(ascii)
+	if len(ascii) > maxDomain {
+		return "", ReasonTooLong
+	}
+
+	labels := strings.Split(ascii, ".")
+	for _, label := range labels {
+		if !validLabel(label) {
+			return "", ReasonDomain
+		}
+	}
+	if len(labels) < 2 {
+		return "", ReasonTLD
+	}
+	if tld := labels[len(labels)-1]; strings.Trim(tld, "0123456789") == "" {
+		// An IP address rather than a domain name.
+		return "", ReasonDomain
+	}
+	return ascii, ""
+}
+
+// validLabel reports whether label is a valid DNS label.
+func validLabel(label string) bool {
+	if label == "" || len(label) > maxLabel || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for _, r := range label {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Options selects the optional checks of Check.
+type Options struct {
+	// Disposable rejects the addresses of disposable address providers.
+	Disposable bool
+	// Role rejects role addresses such as postmaster@ or info@.
+	Role bool
+}
+
+// Check returns an *Error when the normalized address must be rejected under o.
+func (o Options) Check(address string) error {
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return &Error{address, ReasonSyntax}
+	}
+
+	if o.Disposable && IsDisposable(address[at+1:]) {
+		return &Error{address, ReasonDisposable}
+	}
+	if o.Role && IsRole(address[:at]) {
+		return &Error{address, ReasonRole}
+	}
+	return nil
+}
+
+// IsDisposable reports whether domain, or a domain it belongs to, is a
+// disposable address provider of the bundled list.
+func IsDisposable(domain string) bool {
+	domain = strings.ToLower(domain)
+	for {
+		if disposableDomains[domain] {
+			return true
+		}
+		dot := strings.Index(domain, ".")
+		if dot < 0 {
+			return false
+		}
+		domain = domain[dot+1:]
+	}
+}
+
+// IsRole reports whether local, the part of an address before the @, names a
+// role of the bundled list rather than a person. Subaddresses are ignored, so
+// `support+eu` is a role.
+func IsRole(local string) bool {
+	local = strings.ToLower(local)
+	if plus := strings.Index(local, "+"); plus > 0 {
+		local = local[:plus]
+	}
+	return roleLocalParts[local]
+}
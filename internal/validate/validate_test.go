@@ -0,0 +1,128 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	long := strings.Repeat("a", 63)
+	tests := []struct {
+		address string
+		want    string
+		reason  string
+	}{
+		{"name@example.com", "name@example.com", ""},
+		{"  Name@Example.COM\t", "Name@example.com", ""},
+		{"name@example.com.", "name@example.com", ""},
+		{"", "", ReasonEmpty},
+		{" \t", "", ReasonEmpty},
+
+		// Stray quotes and punctuation
+		{"'name@example.com'", "name@example.com", ""},
+		{"name@example.com'", "name@example.com", ""},
+		{`"name@example.com"`, "name@example.com", ""},
+		{"<name@example.com>", "name@example.com", ""},
+		{"`name@example.com`,", "name@example.com", ""},
+		{"name@example.com;", "name@example.com", ""},
+		{"'name@example'", "", ReasonTLD},
+
+		// mailto: and display names
+		{"mailto:name@example.com", "name@example.com", ""},
+		{"MAILTO:name@example.com", "name@example.com", ""},
+		{"Jane Doe <jane@example.com>", "jane@example.com", ""},
+		{`"Doe, Jane" <jane@example.com>`, "jane@example.com", ""},
+
+		// Quoted local parts
+		{`"john doe"@example.com`, `"john doe"@example.com`, ""},
+		{`"john"@example.com`, "john@example.com", ""},
+		{`"john..doe"@example.com`, `"john..doe"@example.com`, ""},
+		{`"john\"doe"@example.com`, `"john\"doe"@example.com`, ""},
+		{"o'brien@example.com", "o'brien@example.com", ""},
+
+		// IDN
+		{"josé@Bücher.de", "josé@xn--bcher-kva.de", ""},
+		{"name@xn--bcher-kva.de", "name@xn--bcher-kva.de", ""},
+		{"name@bad_domain.com", "", ReasonDomain},
+
+		// Length limits
+		{long + "a@example.com", long + "a@example.com", ""},
+		{long + "aa@example.com", "", ReasonLocalPart},
+		{"name@" + long + ".com", "name@" + long + ".com", ""},
+		{"name@" + long + "a.com", "", ReasonDomain},
+		{long + "@" + strings.Repeat(long+".", 3) + "com", "", ReasonTooLong},
+		{"name@" + strings.Repeat(long+".", 4) + "com", "", ReasonTooLong},
+
+		// IP and literal domains
+		{"name@192.0.2.1", "", ReasonDomain},
+		{"name@[192.0.2.1]", "", ReasonDomain},
+		{"name@[IPv6:2001:db8::1]", "", ReasonDomain},
+		{"name@localhost", "", ReasonTLD},
+
+		// Syntax
+		{"name", "", ReasonSyntax},
+		{"@example.com", "", ReasonSyntax},
+		{"name@", "", ReasonSyntax},
+		{"name@@example.com", "", ReasonSyntax},
+		{"john doe@example.com", "", ReasonSyntax},
+	}
+
+	for _, test := range tests {
+		got, err := Normalize(test.address)
+		reason := ""
+		if err != nil {
+			e, ok := err.(*Error)
+			if !ok {
+				t.Errorf("Normalize(%q) returned %T, want *Error", test.address, err)
+				continue
+			}
+			if e.Address != test.address {
+				t.Errorf("Normalize(%q) error names %q", test.address, e.Address)
+			}
+			reason = e.Reason
+		}
+		if got != test.want || reason != test.reason {
+			t.Errorf("Normalize(%q) = %q, %q, want %q, %q", test.address, got, reason, test.want, test.reason)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		address string
+		options Options
+		reason  string
+	}{
+		{"name@mailinator.com", Options{}, ""},
+		{"name@mailinator.com", Options{Disposable: true}, ReasonDisposable},
+		{"name@eu.mailinator.com", Options{Disposable: true}, ReasonDisposable},
+		{"name@notmailinator.com", Options{Disposable: true}, ""},
+		{"info@example.com", Options{}, ""},
+		{"info@example.com", Options{Role: true}, ReasonRole},
+		{"Postmaster+eu@example.com", Options{Role: true}, ReasonRole},
+		{"information@example.com", Options{Role: true}, ""},
+		{"info@mailinator.com", Options{Disposable: true, Role: true}, ReasonDisposable},
+		{"name", Options{}, ReasonSyntax},
+	}
+
+	for _, test := range tests {
+		reason := ""
+		if err := test.options.Check(test.address); err != nil {
+			reason = err.(*Error).Reason
+		}
+		if reason != test.reason {
+			t.Errorf("%+v.Check(%q) = %q, want %q", test.options, test.address, reason, test.reason)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	for reason := range descriptions {
+		if !IsReason(reason) || Describe(reason) == reason {
+			t.Errorf("reason %q has no description", reason)
+		}
+	}
+	if IsReason("header") || Describe("header") != "header" {
+		t.Error("unknown reasons must be described as themselves")
+	}
+}
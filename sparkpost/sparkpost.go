@@ -0,0 +1,12 @@
+// Command sparkpost is the SparkPost command line interface.
+package main
+
+import (
+	"os"
+
+	"github.com/SparkPost/sparkpost-cli/internal/commands"
+)
+
+func main() {
+	commands.NewApp().Run(os.Args)
+}
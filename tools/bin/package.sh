@@ -25,6 +25,11 @@ mkdir $BASE_DIR/sparkpost_cli/osx
 export GOOS="darwin"
 
 
+cd $BASE_DIR/sparkpost
+rm -f sparkpost
+go build
+mv sparkpost $BASE_DIR/sparkpost_cli/osx
+
 cd $BASE_DIR/sp-webhook-cli
 rm -f sp-webhook-cli
 go build
@@ -58,6 +63,11 @@ echo "Building for Linux"
 mkdir $BASE_DIR/sparkpost_cli/linux
 export GOOS="linux"
 
+cd $BASE_DIR/sparkpost
+rm -f sparkpost
+go build
+mv sparkpost $BASE_DIR/sparkpost_cli/linux
+
 cd $BASE_DIR/sp-webhook-cli
 rm -f sp-webhook-cli
 go build
@@ -88,6 +98,11 @@ echo "Building for Windows"
 mkdir $BASE_DIR/sparkpost_cli/windows
 export GOOS="windows"
 
+cd $BASE_DIR/sparkpost
+rm -f sparkpost.exe
+go build
+mv sparkpost.exe $BASE_DIR/sparkpost_cli/windows
+
 cd $BASE_DIR/sp-webhook-cli
 rm -f sp-webhook-cli.exe
 go build